@@ -0,0 +1 @@
+ALTER TABLE sons DROP COLUMN field_changes;
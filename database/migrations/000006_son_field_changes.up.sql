@@ -0,0 +1 @@
+ALTER TABLE sons ADD COLUMN field_changes JSON NULL;
@@ -0,0 +1,6 @@
+DELETE FROM son_execution_logs WHERE execution_status = 'skipped';
+ALTER TABLE son_execution_logs DROP CHECK chk_execution_status;
+ALTER TABLE son_execution_logs ADD CONSTRAINT chk_execution_status CHECK (execution_status IN ('success', 'failure'));
+ALTER TABLE son_execution_logs DROP COLUMN skip_reason;
+
+ALTER TABLE sons DROP COLUMN conditions;
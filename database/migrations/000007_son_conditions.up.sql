@@ -0,0 +1,5 @@
+ALTER TABLE sons ADD COLUMN conditions JSON NULL;
+
+ALTER TABLE son_execution_logs ADD COLUMN skip_reason TEXT NULL;
+ALTER TABLE son_execution_logs DROP CHECK chk_execution_status;
+ALTER TABLE son_execution_logs ADD CONSTRAINT chk_execution_status CHECK (execution_status IN ('success', 'failure', 'skipped'));
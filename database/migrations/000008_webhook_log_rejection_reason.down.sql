@@ -0,0 +1 @@
+ALTER TABLE webhook_logs DROP COLUMN rejection_reason;
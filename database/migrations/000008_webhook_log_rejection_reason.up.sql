@@ -0,0 +1 @@
+ALTER TABLE webhook_logs ADD COLUMN rejection_reason VARCHAR(50) NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE webhooks
+    DROP COLUMN label,
+    DROP COLUMN previous_secret,
+    DROP COLUMN previous_secret_expires_at;
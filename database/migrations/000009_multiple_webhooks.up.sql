@@ -0,0 +1,6 @@
+ALTER TABLE webhooks
+    ADD COLUMN label VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN previous_secret VARCHAR(255) NULL,
+    ADD COLUMN previous_secret_expires_at TIMESTAMP NULL;
+
+UPDATE webhooks SET label = 'Default';
@@ -0,0 +1,5 @@
+ALTER TABLE webhook_logs
+    DROP COLUMN processing_status,
+    DROP COLUMN processing_error,
+    DROP COLUMN processing_attempts,
+    DROP COLUMN processed_at;
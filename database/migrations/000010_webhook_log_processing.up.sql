@@ -0,0 +1,5 @@
+ALTER TABLE webhook_logs
+    ADD COLUMN processing_status VARCHAR(20) NULL,
+    ADD COLUMN processing_error TEXT NULL,
+    ADD COLUMN processing_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN processed_at TIMESTAMP NULL;
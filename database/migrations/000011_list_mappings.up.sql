@@ -0,0 +1,12 @@
+CREATE TABLE list_mappings (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    source_value VARCHAR(255) NOT NULL,
+    list_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_list_mapping (user_id, source_type, source_value, list_id),
+    CONSTRAINT chk_list_mapping_source CHECK (source_type IN ('label', 'newsletter', 'tier'))
+);
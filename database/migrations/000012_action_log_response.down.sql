@@ -0,0 +1,3 @@
+ALTER TABLE son_execution_action_logs
+    DROP COLUMN response_status,
+    DROP COLUMN response_body;
@@ -0,0 +1,3 @@
+ALTER TABLE son_execution_action_logs
+    ADD COLUMN response_status INT NULL,
+    ADD COLUMN response_body TEXT NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE campaign_approvals (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    son_id VARCHAR(36) NULL,
+    son_execution_log_id VARCHAR(36) NULL,
+    campaign_id INT NOT NULL,
+    campaign_name VARCHAR(255) NOT NULL,
+    test_emails JSON NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    decided_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (son_id) REFERENCES sons(id) ON DELETE SET NULL,
+    INDEX idx_campaign_approvals_user_status (user_id, status),
+    CONSTRAINT chk_campaign_approval_status CHECK (status IN ('pending', 'approved', 'rejected', 'expired'))
+);
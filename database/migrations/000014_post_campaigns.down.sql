@@ -0,0 +1,5 @@
+UPDATE campaign_approvals SET status = 'rejected' WHERE status = 'cancelled';
+ALTER TABLE campaign_approvals DROP CHECK chk_campaign_approval_status;
+ALTER TABLE campaign_approvals ADD CONSTRAINT chk_campaign_approval_status CHECK (status IN ('pending', 'approved', 'rejected', 'expired'));
+
+DROP TABLE IF EXISTS post_campaigns;
@@ -0,0 +1,17 @@
+CREATE TABLE post_campaigns (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    son_id VARCHAR(36) NOT NULL,
+    action_index INT NOT NULL,
+    post_id VARCHAR(64) NOT NULL,
+    campaign_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (son_id) REFERENCES sons(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_post_campaign (son_id, action_index, post_id),
+    INDEX idx_post_campaigns_post (user_id, post_id)
+);
+
+ALTER TABLE campaign_approvals DROP CHECK chk_campaign_approval_status;
+ALTER TABLE campaign_approvals ADD CONSTRAINT chk_campaign_approval_status CHECK (status IN ('pending', 'approved', 'rejected', 'expired', 'cancelled'));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS campaign_stats;
+
+ALTER TABLE son_execution_action_logs
+    DROP INDEX idx_action_logs_campaign,
+    DROP COLUMN campaign_id;
@@ -0,0 +1,24 @@
+ALTER TABLE son_execution_action_logs
+    ADD COLUMN campaign_id INT NULL,
+    ADD INDEX idx_action_logs_campaign (campaign_id);
+
+CREATE TABLE campaign_stats (
+    campaign_id INT PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    son_id VARCHAR(36) NULL,
+    post_id VARCHAR(64) NULL,
+    post_title VARCHAR(255) NULL,
+    campaign_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    sent INT NOT NULL DEFAULT 0,
+    views INT NOT NULL DEFAULT 0,
+    clicks INT NOT NULL DEFAULT 0,
+    bounces INT NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (son_id) REFERENCES sons(id) ON DELETE SET NULL,
+    INDEX idx_campaign_stats_son (user_id, son_id),
+    INDEX idx_campaign_stats_post (user_id, post_id)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS son_execution_steps;
+
+ALTER TABLE son_execution_logs
+    DROP INDEX idx_son_execution_logs_member,
+    DROP COLUMN member_email;
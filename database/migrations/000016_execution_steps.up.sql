@@ -0,0 +1,21 @@
+ALTER TABLE son_execution_logs
+    ADD COLUMN member_email VARCHAR(255) NULL,
+    ADD INDEX idx_son_execution_logs_member (member_email);
+
+CREATE TABLE son_execution_steps (
+    id VARCHAR(36) PRIMARY KEY,
+    son_execution_log_id VARCHAR(36) NOT NULL,
+    action_index INT NOT NULL,
+    action_type VARCHAR(50) NOT NULL,
+    task_id VARCHAR(64) NULL,
+    queue VARCHAR(50) NOT NULL DEFAULT 'default',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    scheduled_for TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NULL,
+    error_message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (son_execution_log_id) REFERENCES son_execution_logs(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_execution_step (son_execution_log_id, action_index),
+    CONSTRAINT chk_execution_step_status CHECK (status IN ('queued', 'succeeded', 'failed'))
+);
@@ -0,0 +1,9 @@
+DELETE FROM son_execution_action_logs WHERE action_status = 'cancelled';
+ALTER TABLE son_execution_action_logs DROP CHECK chk_action_status;
+ALTER TABLE son_execution_action_logs ADD CONSTRAINT chk_action_status CHECK (action_status IN ('success', 'failure'));
+
+UPDATE son_execution_steps SET status = 'failed' WHERE status = 'cancelled';
+ALTER TABLE son_execution_steps DROP CHECK chk_execution_step_status;
+ALTER TABLE son_execution_steps ADD CONSTRAINT chk_execution_step_status CHECK (status IN ('queued', 'succeeded', 'failed'));
+
+ALTER TABLE sons DROP COLUMN exit_conditions;
@@ -0,0 +1,7 @@
+ALTER TABLE sons ADD COLUMN exit_conditions JSON NULL;
+
+ALTER TABLE son_execution_steps DROP CHECK chk_execution_step_status;
+ALTER TABLE son_execution_steps ADD CONSTRAINT chk_execution_step_status CHECK (status IN ('queued', 'succeeded', 'failed', 'cancelled'));
+
+ALTER TABLE son_execution_action_logs DROP CHECK chk_action_status;
+ALTER TABLE son_execution_action_logs ADD CONSTRAINT chk_action_status CHECK (action_status IN ('success', 'failure', 'cancelled'));
@@ -0,0 +1,17 @@
+UPDATE son_execution_steps SET status = 'queued' WHERE status IN ('pending', 'running');
+UPDATE son_execution_steps SET status = 'cancelled' WHERE status = 'skipped';
+ALTER TABLE son_execution_steps DROP CHECK chk_execution_step_status;
+ALTER TABLE son_execution_steps ADD CONSTRAINT chk_execution_step_status CHECK (status IN ('queued', 'succeeded', 'failed', 'cancelled'));
+
+ALTER TABLE son_execution_action_logs DROP CHECK chk_action_status;
+DELETE FROM son_execution_action_logs WHERE action_status IN ('pending', 'queued', 'running', 'skipped');
+UPDATE son_execution_action_logs SET action_status = 'success' WHERE action_status = 'succeeded';
+UPDATE son_execution_action_logs SET action_status = 'failure' WHERE action_status = 'failed';
+ALTER TABLE son_execution_action_logs MODIFY action_status VARCHAR(10) NOT NULL;
+ALTER TABLE son_execution_action_logs ADD CONSTRAINT chk_action_status CHECK (action_status IN ('success', 'failure', 'cancelled'));
+
+ALTER TABLE son_execution_logs DROP CHECK chk_execution_status;
+UPDATE son_execution_logs SET execution_status = 'failure' WHERE execution_status IN ('failed', 'partially_failed');
+UPDATE son_execution_logs SET execution_status = 'success' WHERE execution_status NOT IN ('failure', 'skipped');
+ALTER TABLE son_execution_logs MODIFY execution_status VARCHAR(10) NOT NULL;
+ALTER TABLE son_execution_logs ADD CONSTRAINT chk_execution_status CHECK (execution_status IN ('success', 'failure', 'skipped'));
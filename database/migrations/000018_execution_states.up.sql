@@ -0,0 +1,14 @@
+ALTER TABLE son_execution_logs DROP CHECK chk_execution_status;
+ALTER TABLE son_execution_logs MODIFY execution_status VARCHAR(20) NOT NULL;
+UPDATE son_execution_logs SET execution_status = 'succeeded' WHERE execution_status = 'success';
+UPDATE son_execution_logs SET execution_status = 'failed' WHERE execution_status = 'failure';
+ALTER TABLE son_execution_logs ADD CONSTRAINT chk_execution_status CHECK (execution_status IN ('pending', 'queued', 'running', 'succeeded', 'failed', 'partially_failed', 'cancelled', 'skipped'));
+
+ALTER TABLE son_execution_action_logs DROP CHECK chk_action_status;
+ALTER TABLE son_execution_action_logs MODIFY action_status VARCHAR(20) NOT NULL;
+UPDATE son_execution_action_logs SET action_status = 'succeeded' WHERE action_status = 'success';
+UPDATE son_execution_action_logs SET action_status = 'failed' WHERE action_status = 'failure';
+ALTER TABLE son_execution_action_logs ADD CONSTRAINT chk_action_status CHECK (action_status IN ('pending', 'queued', 'running', 'succeeded', 'failed', 'cancelled', 'skipped'));
+
+ALTER TABLE son_execution_steps DROP CHECK chk_execution_step_status;
+ALTER TABLE son_execution_steps ADD CONSTRAINT chk_execution_step_status CHECK (status IN ('pending', 'queued', 'running', 'succeeded', 'failed', 'cancelled', 'skipped'));
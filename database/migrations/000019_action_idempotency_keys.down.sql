@@ -0,0 +1 @@
+DROP TABLE IF EXISTS son_action_idempotency_keys;
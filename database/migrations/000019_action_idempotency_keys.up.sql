@@ -0,0 +1,9 @@
+CREATE TABLE son_action_idempotency_keys (
+    son_execution_log_id VARCHAR(36) NOT NULL,
+    action_index INT NOT NULL,
+    idempotency_key VARCHAR(64) NOT NULL,
+    result VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (son_execution_log_id, action_index, idempotency_key),
+    FOREIGN KEY (son_execution_log_id) REFERENCES son_execution_logs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+)
+
+type ActionHandler struct{}
+
+func NewActionHandler() *ActionHandler {
+	return &ActionHandler{}
+}
+
+// List returns every action a Son can run, with its required parameters
+func (h *ActionHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": models.ActionDefinitions()})
+}
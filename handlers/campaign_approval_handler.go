@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/services"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type CampaignApprovalHandler struct {
+	service *services.CampaignApprovalService
+}
+
+func NewCampaignApprovalHandler(service *services.CampaignApprovalService) *CampaignApprovalHandler {
+	return &CampaignApprovalHandler{service: service}
+}
+
+// List returns the user's campaign approvals. Only pending ones are returned
+// unless ?status= names another status, or "all".
+func (h *CampaignApprovalHandler) List(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	status := models.CampaignApprovalStatus(c.DefaultQuery("status", string(models.CampaignApprovalPending)))
+	if status == "all" {
+		status = ""
+	}
+
+	approvals, err := h.service.List(currentUser.ID, status)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to list campaign approvals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list campaign approvals"})
+		return
+	}
+
+	if approvals == nil {
+		approvals = []models.CampaignApproval{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": approvals})
+}
+
+// Approve schedules the draft campaign
+func (h *CampaignApprovalHandler) Approve(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	approval, err := h.service.Approve(c.Param("id"), currentUser.ID)
+	if err != nil {
+		respondCampaignApprovalError(c, "Failed to approve campaign", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": approval})
+}
+
+// Reject discards the draft campaign
+func (h *CampaignApprovalHandler) Reject(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	approval, err := h.service.Reject(c.Param("id"), currentUser.ID)
+	if err != nil {
+		respondCampaignApprovalError(c, "Failed to reject campaign", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": approval})
+}
+
+func respondCampaignApprovalError(c *gin.Context, message string, err error) {
+	switch err {
+	case services.ErrCampaignApprovalNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign approval not found"})
+	case services.ErrCampaignApprovalNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "Campaign approval is no longer pending"})
+	default:
+		utils.ErrorLogger.Errorf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
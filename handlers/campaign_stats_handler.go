@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/services"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type CampaignStatsHandler struct {
+	service *services.CampaignStatsService
+}
+
+func NewCampaignStatsHandler(service *services.CampaignStatsService) *CampaignStatsHandler {
+	return &CampaignStatsHandler{service: service}
+}
+
+// SummarizeBySon returns the campaign totals of every Son
+func (h *CampaignStatsHandler) SummarizeBySon(c *gin.Context) {
+	h.summarize(c, h.service.SummarizeBySon)
+}
+
+// SummarizeByPost returns the campaign totals of every post
+func (h *CampaignStatsHandler) SummarizeByPost(c *gin.Context) {
+	h.summarize(c, h.service.SummarizeByPost)
+}
+
+// ListBySon returns the campaigns of a Son with their stats
+func (h *CampaignStatsHandler) ListBySon(c *gin.Context) {
+	h.list(c, h.service.ListBySon)
+}
+
+// ListByPost returns the campaigns of a post with their stats
+func (h *CampaignStatsHandler) ListByPost(c *gin.Context) {
+	h.list(c, h.service.ListByPost)
+}
+
+func (h *CampaignStatsHandler) summarize(c *gin.Context, summarize func(userID string) ([]models.CampaignStatsSummary, error)) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	summaries, err := summarize(currentUser.ID)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to summarize campaign stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get campaign stats"})
+		return
+	}
+
+	if summaries == nil {
+		summaries = []models.CampaignStatsSummary{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
+}
+
+func (h *CampaignStatsHandler) list(c *gin.Context, list func(userID string, id string) ([]models.CampaignStats, error)) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	campaigns, err := list(currentUser.ID, c.Param("id"))
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to list campaign stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get campaign stats"})
+		return
+	}
+
+	if campaigns == nil {
+		campaigns = []models.CampaignStats{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": campaigns})
+}
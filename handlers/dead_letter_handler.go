@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/services"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type DeadLetterHandler struct {
+	service *services.DeadLetterService
+}
+
+func NewDeadLetterHandler(service *services.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// deadLetterBatch selects dead letters by ID. No IDs selects all of them.
+type deadLetterBatch struct {
+	IDs []string `json:"ids"`
+}
+
+// List returns the user's dead-lettered actions with their payload and
+// last error
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	deadLetters, total, err := h.service.List(currentUser.ID, limit, offset)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to list dead letters: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead letters"})
+		return
+	}
+
+	if deadLetters == nil {
+		deadLetters = []models.DeadLetter{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": deadLetters,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
+
+// Retry runs a dead-lettered action again
+func (h *DeadLetterHandler) Retry(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	if err := h.service.Retry(currentUser.ID, c.Param("id")); err != nil {
+		respondDeadLetterError(c, "Failed to retry action", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Discard deletes a dead-lettered action
+func (h *DeadLetterHandler) Discard(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	if err := h.service.Discard(currentUser.ID, c.Param("id")); err != nil {
+		respondDeadLetterError(c, "Failed to discard action", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RetryMany runs the selected dead-lettered actions again
+func (h *DeadLetterHandler) RetryMany(c *gin.Context) {
+	h.applyMany(c, "retried", h.service.RetryMany)
+}
+
+// DiscardMany deletes the selected dead-lettered actions
+func (h *DeadLetterHandler) DiscardMany(c *gin.Context) {
+	h.applyMany(c, "discarded", h.service.DiscardMany)
+}
+
+func (h *DeadLetterHandler) applyMany(c *gin.Context, verb string, apply func(userID string, ids []string) (int, error)) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var batch deadLetterBatch
+	if err := c.ShouldBindJSON(&batch); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	count, err := apply(currentUser.ID, batch.IDs)
+	if err != nil {
+		// Part of the batch may have gone through, report both
+		utils.ErrorLogger.Errorf("Failed to apply dead letter batch: %v", err)
+		c.JSON(http.StatusMultiStatus, gin.H{"data": gin.H{verb: count}, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{verb: count}})
+}
+
+func respondDeadLetterError(c *gin.Context, message string, err error) {
+	switch err {
+	case services.ErrDeadLetterNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+	default:
+		utils.ErrorLogger.Errorf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -17,19 +17,33 @@ type Handlers struct {
 	SonExecutionLog *SonExecutionLogHandler
 	RecentActivity *RecentActivityHandler
 	SonStats		*SonStatsHandler
+	Trigger         *TriggerHandler
+	Action          *ActionHandler
+	ListMapping     *ListMappingHandler
+	CampaignApproval *CampaignApprovalHandler
+	CampaignStats    *CampaignStatsHandler
+	TemplatePreview  *TemplatePreviewHandler
+	DeadLetter       *DeadLetterHandler
 }
 
 func NewHandlers(services *services.Services) *Handlers {
 	return &Handlers{
 		Auth:            NewAuthHandler(services.User, services.MagicLink, services.Email),
 		Son:             NewSonHandler(services.SonStorage),
-		Webhook:         NewWebhookHandler(services.SonStorage, services.SonExecutor, services.Webhook, services.WebhookLogger),
+		Webhook:         NewWebhookHandler(services.SonStorage, services.SonExecutor, services.Webhook, services.WebhookLogger, services.SignatureVerifier, services.Deduplicator),
 		Listmonk:        NewListmonkHandler(services.ListmonkClient),
 		Home:            NewHomeHandler(),
 		WebhookLog:      NewWebhookLogHandler(services.WebhookLogger),
 		SonExecutionLog: NewSonExecutionLogHandler(services.SonExecutionLogger),
 		RecentActivity: NewRecentActivityHandler(services.RecentActivity),
 		SonStats:		NewSonStatsHandler(services.SonExecutionLogger),
+		Trigger:         NewTriggerHandler(),
+		Action:          NewActionHandler(),
+		ListMapping:     NewListMappingHandler(services.ListMappings),
+		CampaignApproval: NewCampaignApprovalHandler(services.CampaignApprovals),
+		CampaignStats:    NewCampaignStatsHandler(services.CampaignStats),
+		TemplatePreview:  NewTemplatePreviewHandler(services.WebhookLogger),
+		DeadLetter:       NewDeadLetterHandler(services.DeadLetters),
 	}
 }
 
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/services"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type ListMappingHandler struct {
+	service *services.ListMappingService
+}
+
+func NewListMappingHandler(service *services.ListMappingService) *ListMappingHandler {
+	return &ListMappingHandler{service: service}
+}
+
+type listMappingRequest struct {
+	SourceType  models.ListMappingSource `json:"source_type" binding:"required"`
+	SourceValue string                   `json:"source_value" binding:"required"`
+	ListID      int                      `json:"list_id" binding:"required"`
+}
+
+// List returns the label, newsletter and tier to list mappings of the user
+func (h *ListMappingHandler) List(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	mappings, err := h.service.List(currentUser.ID)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to list list mappings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list list mappings"})
+		return
+	}
+
+	if mappings == nil {
+		mappings = []models.ListMapping{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mappings})
+}
+
+func (h *ListMappingHandler) Create(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req listMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping := models.ListMapping{
+		UserID:      currentUser.ID,
+		SourceType:  req.SourceType,
+		SourceValue: req.SourceValue,
+		ListID:      req.ListID,
+	}
+	if err := mapping.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Create(&mapping); err != nil {
+		respondListMappingError(c, "Failed to create list mapping", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": mapping})
+}
+
+func (h *ListMappingHandler) Update(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req listMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := h.service.Get(c.Param("id"), currentUser.ID)
+	if err != nil {
+		respondListMappingError(c, "Failed to update list mapping", err)
+		return
+	}
+
+	mapping.SourceType = req.SourceType
+	mapping.SourceValue = req.SourceValue
+	mapping.ListID = req.ListID
+	if err := mapping.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Update(mapping); err != nil {
+		respondListMappingError(c, "Failed to update list mapping", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": mapping})
+}
+
+func (h *ListMappingHandler) Delete(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	if err := h.service.Delete(c.Param("id"), currentUser.ID); err != nil {
+		respondListMappingError(c, "Failed to delete list mapping", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "List mapping deleted successfully"})
+}
+
+func respondListMappingError(c *gin.Context, message string, err error) {
+	if err == services.ErrListMappingNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "List mapping not found"})
+		return
+	}
+	if err == services.ErrListMappingExists {
+		c.JSON(http.StatusConflict, gin.H{"error": "List mapping already exists"})
+		return
+	}
+	utils.ErrorLogger.Errorf("%s: %v", message, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -33,7 +33,9 @@ func (h *SonExecutionLogHandler) GetSonExecutionLogs(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	logs, total, err := h.logger.GetSonExecutionLogs(currentUser.ID, limit, offset)
+	memberEmail := c.Query("member_email")
+
+	logs, total, err := h.logger.GetSonExecutionLogs(currentUser.ID, memberEmail, limit, offset)
 	if err != nil {
 		utils.ErrorLogger.Printf("Failed to fetch son execution logs: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch son execution logs"})
@@ -70,3 +72,28 @@ func (h *SonExecutionLogHandler) GetActionExecutionLogs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"logs": logs})
 }
+
+// GetExecutionSteps lists the steps of an execution, showing how far the
+// member has got through the Son's sequence
+func (h *SonExecutionLogHandler) GetExecutionSteps(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	steps, err := h.logger.GetExecutionSteps(currentUser.ID, c.Param("executionId"))
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to fetch execution steps: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch execution steps"})
+		return
+	}
+
+	if steps == nil {
+		steps = []models.ExecutionStep{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"steps": steps})
+}
@@ -34,6 +34,12 @@ func (h *SonHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if err := son.Validate(); err != nil {
+		utils.ErrorLogger.Errorf("Invalid Son data: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	son.ID = utils.GenerateUUID()
 	son.UserID = currentUser.ID
 
@@ -123,6 +129,12 @@ func (h *SonHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if err := son.Validate(); err != nil {
+		utils.ErrorLogger.Errorf("Invalid Son data: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	son.ID = id
 	son.UserID = currentUser.ID
 	if err := h.storage.Update(son); err != nil {
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/services"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type TemplatePreviewHandler struct {
+	webhookLogger *services.WebhookLogger
+}
+
+func NewTemplatePreviewHandler(webhookLogger *services.WebhookLogger) *TemplatePreviewHandler {
+	return &TemplatePreviewHandler{webhookLogger: webhookLogger}
+}
+
+type templatePreviewRequest struct {
+	Subject string             `json:"subject"`
+	Body    string             `json:"body"`
+	Trigger models.TriggerType `json:"trigger"`
+	SonName string             `json:"son_name"`
+	// WebhookLogID renders against a logged delivery instead of the sample
+	// payload of the trigger
+	WebhookLogID string `json:"webhook_log_id"`
+}
+
+// Preview renders a campaign subject and body against a logged webhook
+// payload or the sample payload of a trigger. Template errors are part of
+// the response rather than failing the request.
+func (h *TemplatePreviewHandler) Preview(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req templatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payload []byte
+	if req.WebhookLogID != "" {
+		log, err := h.webhookLogger.GetWebhookLogDetails(req.WebhookLogID)
+		if err == sql.ErrNoRows || (err == nil && log.UserID != currentUser.ID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook log not found"})
+			return
+		}
+		if err != nil {
+			utils.ErrorLogger.Errorf("Failed to get webhook log for preview: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook log"})
+			return
+		}
+		payload = []byte(log.Body)
+	} else {
+		def, ok := models.GetTriggerDefinition(req.Trigger)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A valid trigger or a webhook_log_id is required"})
+			return
+		}
+		payload = def.SamplePayload
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Webhook payload is not valid JSON"})
+		return
+	}
+
+	templateContext, err := models.NewTemplateContext(models.TemplateSon{Name: req.SonName, Trigger: req.Trigger}, data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.PreviewCampaign(req.Subject, req.Body, templateContext)})
+}
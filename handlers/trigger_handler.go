@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/troneras/ghost-listmonk-connector/models"
+)
+
+type TriggerHandler struct{}
+
+func NewTriggerHandler() *TriggerHandler {
+	return &TriggerHandler{}
+}
+
+// List returns every trigger a Son can subscribe to, with a sample payload
+func (h *TriggerHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": models.TriggerDefinitions()})
+}
@@ -2,10 +2,8 @@ package handlers
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,18 +17,22 @@ import (
 )
 
 type WebhookHandler struct {
-	sonStorage     *services.SonStorage
-	executor       *services.SonExecutor
-	webhookService *services.WebhookService
-	webhookLogger  *services.WebhookLogger
+	sonStorage        *services.SonStorage
+	executor          *services.SonExecutor
+	webhookService    *services.WebhookService
+	webhookLogger     *services.WebhookLogger
+	signatureVerifier *services.WebhookSignatureVerifier
+	deduplicator      *services.WebhookDeduplicator
 }
 
-func NewWebhookHandler(sonStorage *services.SonStorage, executor *services.SonExecutor, webhookService *services.WebhookService, webhookLogger *services.WebhookLogger) *WebhookHandler {
+func NewWebhookHandler(sonStorage *services.SonStorage, executor *services.SonExecutor, webhookService *services.WebhookService, webhookLogger *services.WebhookLogger, signatureVerifier *services.WebhookSignatureVerifier, deduplicator *services.WebhookDeduplicator) *WebhookHandler {
 	return &WebhookHandler{
-		sonStorage:     sonStorage,
-		executor:       executor,
-		webhookService: webhookService,
-		webhookLogger:  webhookLogger,
+		sonStorage:        sonStorage,
+		executor:          executor,
+		webhookService:    webhookService,
+		webhookLogger:     webhookLogger,
+		signatureVerifier: signatureVerifier,
+		deduplicator:      deduplicator,
 	}
 }
 
@@ -63,9 +65,19 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 
 	// Verify the webhook signature
 	signature := c.GetHeader("x-ghost-signature")
-	if !verifySignature(signature, body, webhook.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
-		h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusUnauthorized, gin.H{"error": "Invalid signature"}, time.Since(startTime))
+	if err := h.signatureVerifier.Verify(c, webhook, signature, body); err != nil {
+		var rejection *utils.CustomError
+		if !errors.As(err, &rejection) {
+			utils.ErrorLogger.Errorf("Failed to verify signature: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"})
+			h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"}, time.Since(startTime))
+			return
+		}
+
+		utils.ErrorLogger.Printf("Rejected webhook %s: %v", webhookLogID, rejection)
+		response := gin.H{"error": "Invalid signature", "reason": rejection.Code}
+		c.JSON(http.StatusUnauthorized, response)
+		h.webhookLogger.RejectWebhookLog(webhookLogID, http.StatusUnauthorized, rejection.Code, response, time.Since(startTime))
 		return
 	}
 
@@ -79,7 +91,14 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 
 	utils.InfoLogger.Infof("Received webhook: %s", utils.PrettyPrint(webhookData))
 
-	triggerType, err := determineTriggerType(webhookData)
+	// Prefer the event named in the URL; payload inspection is only kept for
+	// webhooks still configured against the legacy /webhook/:endpoint URL.
+	var triggerType models.TriggerType
+	if event := c.Param("event"); event != "" {
+		triggerType, err = models.TriggerTypeFromGhostEvent(event)
+	} else {
+		triggerType, err = determineTriggerType(webhookData)
+	}
 	if err != nil {
 		utils.ErrorLogger.Errorf("Unable to determine trigger type: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to determine trigger type"})
@@ -89,30 +108,54 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 
 	utils.InfoLogger.Infof("Determined trigger type: %s", triggerType)
 
-	// Find and execute relevant Sons
-	sons, err := h.sonStorage.List(webhook.UserID)
-	if err != nil {
-		utils.ErrorLogger.Errorf("Failed to list Sons: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list Sons"})
-		h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusInternalServerError, gin.H{"error": "Failed to list Sons"}, time.Since(startTime))
-		return
+	// Ghost retries and replays of an already accepted delivery are dropped,
+	// unless a replay explicitly asked to override the check.
+	dedupKey, dedupable := services.DedupKey(webhook.Endpoint, triggerType, webhookData)
+	if dedupable && h.signatureVerifier.VerifyDedupOverride(webhook, signature, c.GetHeader("X-Webhook-Dedup-Override")) {
+		utils.InfoLogger.Infof("Deduplication overridden for webhook %s", webhookLogID)
+		dedupable = false
 	}
-
-	executedCount := 0
-	for _, son := range sons {
-		if son.Trigger == triggerType && son.Enabled {
-			go func(s models.Son) {
-				utils.InfoLogger.Infof("Executing Son %s for trigger %s", s.ID, triggerType)
-				h.executor.ExecuteSon(s, webhookData, webhookLogID)
-			}(son)
-			executedCount++
+	if dedupable {
+		fresh, err := h.deduplicator.Claim(c, dedupKey, webhookLogID)
+		if err != nil {
+			utils.ErrorLogger.Errorf("Failed to check webhook deduplication: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook"})
+			h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook"}, time.Since(startTime))
+			return
+		}
+		if !fresh {
+			originalLogID := h.deduplicator.OriginalLogID(c, dedupKey)
+			utils.InfoLogger.Infof("Duplicate webhook %s, already accepted as %s", webhookLogID, originalLogID)
+			response := gin.H{"message": "Duplicate webhook ignored", "trigger": triggerType, "original_webhook_log_id": originalLogID}
+			c.JSON(http.StatusOK, response)
+			h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusOK, response, time.Since(startTime))
+			h.webhookLogger.UpdateProcessingStatus(webhookLogID, services.WebhookProcessingDuplicate, "")
+			return
 		}
 	}
 
-	utils.InfoLogger.Infof("Executed %d Sons for trigger %s", executedCount, triggerType)
+	// Persist the delivery as a task before answering Ghost, so it is not
+	// lost if the process stops; Son matching and execution run in the worker.
+	info, err := h.executor.EnqueueWebhook(services.ProcessWebhookPayload{
+		WebhookLogID: webhookLogID,
+		UserID:       webhook.UserID,
+		Trigger:      triggerType,
+		Data:         webhookData,
+	})
+	if err != nil {
+		if dedupable {
+			if err := h.deduplicator.Release(c, dedupKey); err != nil {
+				utils.ErrorLogger.Errorf("Failed to release deduplication key: %v", err)
+			}
+		}
+		utils.ErrorLogger.Errorf("Failed to enqueue webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook"})
+		h.webhookLogger.UpdateWebhookLog(webhookLogID, http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook"}, time.Since(startTime))
+		return
+	}
 
 	// Prepare response
-	response := gin.H{"message": "Webhook processed successfully", "sons_executed": executedCount}
+	response := gin.H{"message": "Webhook accepted", "trigger": triggerType, "task_id": info.ID}
 	c.JSON(http.StatusOK, response)
 
 	// Update the webhook log
@@ -123,8 +166,19 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 	}
 }
 
+// ReplayWebhook sends a logged delivery through the webhook endpoint again.
+// Replays are deduplicated like Ghost deliveries unless ?force=true is set.
 func (h *WebhookHandler) ReplayWebhook(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
 	logID := c.Param("id")
+	force := c.Query("force") == "true"
 
 	// Get the original webhook log
 	log, err := h.webhookLogger.GetWebhookLogForReplay(logID)
@@ -134,6 +188,12 @@ func (h *WebhookHandler) ReplayWebhook(c *gin.Context) {
 		return
 	}
 
+	if log.UserID != currentUser.ID {
+		utils.ErrorLogger.Printf("User %s attempted to replay log %s belonging to another user", currentUser.ID, logID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
 	// Construct the webhook URL
 	scheme := "http"
 	if c.Request.TLS != nil {
@@ -165,6 +225,22 @@ func (h *WebhookHandler) ReplayWebhook(c *gin.Context) {
 	// Add a custom header to indicate this is a replay
 	req.Header.Set("X-Webhook-Replay", "true")
 
+	// The original signature is stale and has already been seen, so sign the
+	// body again with the webhook secret.
+	endpoint := strings.SplitN(strings.TrimPrefix(log.Path, "/webhook/"), "/", 2)[0]
+	webhook, err := h.webhookService.GetWebhookByEndpoint(endpoint)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to get webhook for replay: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	signatureHeader := services.SignatureHeader([]byte(log.Body), webhook.Secret, time.Now())
+	req.Header.Set("X-Ghost-Signature", signatureHeader)
+	req.Header.Del("X-Webhook-Dedup-Override")
+	if force {
+		req.Header.Set("X-Webhook-Dedup-Override", services.DedupOverrideToken(webhook.Secret, signatureHeader))
+	}
+
 	// Send the request to our own webhook endpoint
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -209,43 +285,177 @@ func (h *WebhookHandler) GetWebhookInfo(c *gin.Context) {
 		return
 	}
 
-	webhooks[0].Endpoint = utils.GetConfig().FrontendURL + "/webhook/" + webhooks[0].Endpoint
+	webhooks[0].URL = webhookURL(webhooks[0].Endpoint)
+	webhooks[0].Endpoint = webhooks[0].URL
 
 	// For now, we'll just return the first webhook
 	c.JSON(http.StatusOK, gin.H{"data": webhooks[0]})
 }
 
-func verifySignature(signature string, payload []byte, secret string) bool {
-	// Split the signature into its components
-	parts := strings.Split(signature, ", ")
-	if len(parts) != 2 {
-		utils.ErrorLogger.Println("Invalid signature format")
-		return false
+// ListWebhooks returns every webhook of the current user
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	webhooks, err := h.webhookService.GetWebhooksByUserID(currentUser.ID)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to get webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []models.Webhook{}
+	}
+	for i := range webhooks {
+		webhooks[i].URL = webhookURL(webhooks[i].Endpoint)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": webhooks})
+}
+
+// CreateWebhook adds a new labelled webhook for the current user
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req struct {
+		Label string `json:"label" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(currentUser.ID, req.Label)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to create webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	webhook.URL = webhookURL(webhook.Endpoint)
+	c.JSON(http.StatusCreated, gin.H{"data": webhook})
+}
+
+// UpdateWebhook changes the label of a webhook
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req struct {
+		Label string `json:"label" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhookLabel(c.Param("id"), currentUser.ID, req.Label)
+	if err != nil {
+		respondWebhookError(c, "Failed to update webhook", err)
+		return
+	}
+
+	webhook.URL = webhookURL(webhook.Endpoint)
+	c.JSON(http.StatusOK, gin.H{"data": webhook})
+}
+
+// DeleteWebhook removes a webhook; Ghost deliveries to it will then 404
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
 	}
+	currentUser := user.(*models.User)
 
-	receivedSignature := strings.TrimPrefix(parts[0], "sha256=")
-	timestamp := strings.TrimPrefix(parts[1], "t=")
+	if err := h.webhookService.DeleteWebhook(c.Param("id"), currentUser.ID); err != nil {
+		respondWebhookError(c, "Failed to delete webhook", err)
+		return
+	}
 
-	// Append the timestamp to the payload
-	fullPayload := append(payload, []byte(timestamp)...)
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
 
-	// Compute the expected signature
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(fullPayload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
+// RotateWebhookSecret issues a new secret. The old one stays valid for the
+// requested grace period (24h by default) so Ghost can be reconfigured.
+func (h *WebhookHandler) RotateWebhookSecret(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		utils.ErrorLogger.Println("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	currentUser := user.(*models.User)
 
-	// Compare the signatures
-	if receivedSignature != expectedSignature {
-		utils.ErrorLogger.Printf("Signature mismatch. Received: %s, Expected: %s", receivedSignature, expectedSignature)
-		return false
+	var req struct {
+		GracePeriod string `json:"grace_period"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.GracePeriod == "" {
+		req.GracePeriod = "24h"
 	}
 
-	return true
+	gracePeriod, err := utils.ParseDuration(req.GracePeriod)
+	if err != nil || gracePeriod < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grace period"})
+		return
+	}
+
+	webhook, err := h.webhookService.RotateSecret(c.Param("id"), currentUser.ID, gracePeriod)
+	if err != nil {
+		respondWebhookError(c, "Failed to rotate webhook secret", err)
+		return
+	}
+
+	webhook.URL = webhookURL(webhook.Endpoint)
+	c.JSON(http.StatusOK, gin.H{"data": webhook})
 }
 
+func respondWebhookError(c *gin.Context, message string, err error) {
+	if err == services.ErrWebhookNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	utils.ErrorLogger.Errorf("%s: %v", message, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+func webhookURL(endpoint string) string {
+	return utils.GetConfig().FrontendURL + "/webhook/" + endpoint
+}
+
+// determineTriggerType guesses the trigger from the payload shape. It is only
+// used for webhooks that do not name their event in the URL.
 func determineTriggerType(webhookData map[string]interface{}) (models.TriggerType, error) {
+	// site.changed is the only event Ghost sends with an empty body
+	if len(webhookData) == 0 {
+		utils.InfoLogger.Infof("Site changed")
+		return models.TriggerSiteChanged, nil
+	}
+
 	if member, ok := webhookData["member"].(map[string]interface{}); ok {
-		if _, ok := member["current"].(map[string]interface{}); ok {
+		if current, ok := member["current"].(map[string]interface{}); ok && len(current) > 0 {
 			if previous, ok := member["previous"].(map[string]interface{}); ok && len(previous) > 0 {
 				utils.InfoLogger.Infof("Member updated. Previous data: %v", previous)
 				return models.TriggerMemberUpdated, nil
@@ -258,28 +468,31 @@ func determineTriggerType(webhookData map[string]interface{}) (models.TriggerTyp
 	}
 
 	if post, ok := webhookData["post"].(map[string]interface{}); ok {
-		if current, ok := post["current"].(map[string]interface{}); ok {
-			if status, ok := current["status"].(string); ok {
-				switch status {
-				case "published":
-					utils.InfoLogger.Infof("Post published")
-					return models.TriggerPostPublished, nil
-				case "scheduled":
-					utils.InfoLogger.Infof("Post scheduled")
-					return models.TriggerPostScheduled, nil
-				default:
-					utils.InfoLogger.Infof("Unhandled post status: %s", status)
-				}
-			}
+		if triggerType := determineContentTrigger(post, postTriggers); triggerType != "" {
+			utils.InfoLogger.Infof("Post event: %s", triggerType)
+			return triggerType, nil
 		}
 	}
 
 	if page, ok := webhookData["page"].(map[string]interface{}); ok {
-		if current, ok := page["current"].(map[string]interface{}); ok {
-			if status, ok := current["status"].(string); ok && status == "published" {
-				utils.InfoLogger.Infof("Page published")
-				return models.TriggerPagePublished, nil
-			}
+		if triggerType := determineContentTrigger(page, pageTriggers); triggerType != "" {
+			utils.InfoLogger.Infof("Page event: %s", triggerType)
+			return triggerType, nil
+		}
+	}
+
+	if tag, ok := webhookData["tag"].(map[string]interface{}); ok {
+		current, _ := tag["current"].(map[string]interface{})
+		previous, _ := tag["previous"].(map[string]interface{})
+		switch {
+		case len(current) == 0:
+			utils.InfoLogger.Infof("Tag deleted")
+			return models.TriggerTagDeleted, nil
+		case len(previous) == 0:
+			utils.InfoLogger.Infof("Tag added")
+			return models.TriggerTagAdded, nil
+		default:
+			utils.InfoLogger.Infof("Unhandled tag edit")
 		}
 	}
 
@@ -288,3 +501,61 @@ func determineTriggerType(webhookData map[string]interface{}) (models.TriggerTyp
 
 	return "", utils.NewError("UnknownTriggerType", "Unable to determine trigger type from webhook data")
 }
+
+// contentTriggers lists the triggers a post or page payload can resolve to.
+// An empty trigger means the event is not supported for that resource.
+type contentTriggers struct {
+	published       models.TriggerType
+	publishedEdited models.TriggerType
+	scheduled       models.TriggerType
+	unpublished     models.TriggerType
+	edited          models.TriggerType
+	deleted         models.TriggerType
+}
+
+var postTriggers = contentTriggers{
+	published:       models.TriggerPostPublished,
+	publishedEdited: models.TriggerPostPublishedEdited,
+	scheduled:       models.TriggerPostScheduled,
+	unpublished:     models.TriggerPostUnpublished,
+	edited:          models.TriggerPostEdited,
+	deleted:         models.TriggerPostDeleted,
+}
+
+var pageTriggers = contentTriggers{
+	published:       models.TriggerPagePublished,
+	publishedEdited: models.TriggerPagePublishedEdited,
+	unpublished:     models.TriggerPageUnpublished,
+	edited:          models.TriggerPageEdited,
+	deleted:         models.TriggerPageDeleted,
+}
+
+// determineContentTrigger inspects the status transition between the previous
+// and current versions of a post or page.
+func determineContentTrigger(content map[string]interface{}, triggers contentTriggers) models.TriggerType {
+	current, _ := content["current"].(map[string]interface{})
+	previous, _ := content["previous"].(map[string]interface{})
+
+	if len(current) == 0 {
+		return triggers.deleted
+	}
+
+	status, _ := current["status"].(string)
+	previousStatus, statusChanged := previous["status"].(string)
+
+	switch {
+	case status == "published" && (statusChanged || len(previous) == 0):
+		return triggers.published
+	case status == "published":
+		return triggers.publishedEdited
+	case statusChanged && previousStatus == "published":
+		return triggers.unpublished
+	case status == "scheduled" && statusChanged:
+		return triggers.scheduled
+	case len(previous) > 0:
+		return triggers.edited
+	default:
+		utils.InfoLogger.Infof("Unhandled content status: %s", status)
+		return ""
+	}
+}
@@ -55,6 +55,7 @@ func main() {
 		}
 	}()
 	defer services.SonExecutor.Stop()
+	defer services.DeadLetters.Close()
 
 	// Initialize handlers
 	handlers := handlers.NewHandlers(services)
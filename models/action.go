@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type ActionType string
+
+const (
+	ActionSendTransactionalEmail ActionType = "send_transactional_email"
+	ActionManageSubscriber       ActionType = "manage_subscriber"
+	ActionCreateCampaign         ActionType = "create_campaign"
+	ActionRemoveFromLists        ActionType = "remove_from_lists"
+	ActionUnsubscribeSubscriber  ActionType = "unsubscribe_subscriber"
+	ActionBlocklistSubscriber    ActionType = "blocklist_subscriber"
+	ActionDeleteSubscriber       ActionType = "delete_subscriber"
+	ActionHTTPRequest            ActionType = "http_request"
+)
+
+type Action struct {
+	Type       ActionType     `json:"type"`
+	Parameters map[string]any `json:"parameters"`
+
+	// Delay is how long after the Son delay the action runs, which turns the
+	// actions of a Son into a sequence (e.g. "0s", "3d", "10d").
+	Delay string `json:"delay,omitempty"`
+
+	// Anchor is a payload path to a timestamp the delay counts from instead,
+	// e.g. member.created_at. See Schedule.
+	Anchor string `json:"anchor,omitempty"`
+
+	// SendWindow holds the action back until the time of day it allows
+	SendWindow *SendWindow `json:"send_window,omitempty"`
+
+	// Retry overrides how the action is retried when it fails
+	Retry *RetryPolicy `json:"retry,omitempty"`
+}
+
+// ActionDefinition describes an action type and the parameters it requires
+type ActionDefinition struct {
+	Type               ActionType `json:"type"`
+	Name               string     `json:"name"`
+	RequiredParameters []string   `json:"required_parameters"`
+}
+
+var actionDefinitions = []ActionDefinition{
+	{ActionSendTransactionalEmail, "Send Transactional Email", []string{"template_id"}},
+	{ActionManageSubscriber, "Manage Subscriber", nil},
+	{ActionCreateCampaign, "Create Campaign", []string{"name", "subject", "lists", "template_id", "body"}},
+	{ActionRemoveFromLists, "Remove From Lists", []string{"lists"}},
+	{ActionUnsubscribeSubscriber, "Unsubscribe From All Lists", nil},
+	{ActionBlocklistSubscriber, "Blocklist Subscriber", nil},
+	{ActionDeleteSubscriber, "Delete Subscriber", nil},
+	{ActionHTTPRequest, "HTTP Request", []string{"url"}},
+}
+
+// HTTPRequestMethods are the methods an http_request action may use
+var HTTPRequestMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// ActionDefinitions returns every action type a Son can run
+func ActionDefinitions() []ActionDefinition {
+	return actionDefinitions
+}
+
+// GetActionDefinition looks up the definition of an action type
+func GetActionDefinition(t ActionType) (ActionDefinition, bool) {
+	for _, def := range actionDefinitions {
+		if def.Type == t {
+			return def, true
+		}
+	}
+	return ActionDefinition{}, false
+}
+
+// Validate checks that the action type exists and its required parameters are set
+func (a Action) Validate() error {
+	def, ok := GetActionDefinition(a.Type)
+	if !ok {
+		return fmt.Errorf("unknown action type: %s", a.Type)
+	}
+	for _, param := range def.RequiredParameters {
+		if value, ok := a.Parameters[param]; !ok || value == nil {
+			return fmt.Errorf("action %s is missing the %s parameter", a.Type, param)
+		}
+	}
+	// Only anchored delays may point before their origin
+	if delay, err := a.GetParsedDelay(); err != nil || (delay < 0 && a.Anchor == "") {
+		return fmt.Errorf("invalid delay for action %s: %s", a.Type, a.Delay)
+	}
+	if a.SendWindow != nil {
+		if err := a.SendWindow.Validate(); err != nil {
+			return err
+		}
+	}
+	if a.Retry != nil {
+		if err := a.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+	switch a.Type {
+	case ActionManageSubscriber:
+		if _, err := ParseAttributeMappings(a.Parameters["attribute_mappings"]); err != nil {
+			return err
+		}
+		if mode, ok := a.Parameters["list_mode"]; ok && mode != nil && mode != "" {
+			if mode != "merge" && mode != "replace" {
+				return fmt.Errorf("invalid list_mode: %v, expected merge or replace", mode)
+			}
+		}
+	case ActionCreateCampaign:
+		if expiresIn, ok := a.Parameters["approval_expires_in"].(string); ok && expiresIn != "" {
+			if d, err := utils.ParseDuration(expiresIn); err != nil || d <= 0 {
+				return fmt.Errorf("invalid approval_expires_in: %s", expiresIn)
+			}
+		}
+	case ActionHTTPRequest:
+		return validateHTTPRequest(a.Parameters)
+	}
+	return nil
+}
+
+// GetParsedDelay returns the action delay, zero when it has none
+func (a Action) GetParsedDelay() (time.Duration, error) {
+	if a.Delay == "" {
+		return 0, nil
+	}
+	return utils.ParseDuration(a.Delay)
+}
+
+func validateHTTPRequest(params map[string]any) error {
+	rawURL, _ := params["url"].(string)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("http_request url must be an absolute http or https URL")
+	}
+	// Host names are checked again once resolved, when the request is sent
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && !utils.IsPublicIP(ip)) || strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("http_request url must not point to an internal address")
+	}
+
+	if method, ok := params["method"].(string); ok && method != "" {
+		valid := false
+		for _, m := range HTTPRequestMethods {
+			if strings.EqualFold(method, m) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unsupported http_request method: %s", method)
+		}
+	}
+
+	if body, ok := params["body"].(string); ok && body != "" {
+		if _, err := utils.CompileTextTemplate("body", body); err != nil {
+			return fmt.Errorf("invalid http_request body template: %v", err)
+		}
+	}
+	return nil
+}
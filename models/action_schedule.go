@@ -0,0 +1,177 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	// The runtime image ships without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// memberTimezonePath is where Ghost puts the timezone of a member
+const memberTimezonePath = "member.current.geolocation.timezone"
+
+// SendWindow restricts an action to a daily time range, e.g. 09:00 to 18:00.
+// The range is read in the member's timezone when Ghost knows it, and in
+// Timezone (UTC by default) otherwise. An End before Start spans midnight.
+type SendWindow struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func (w SendWindow) Validate() error {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("invalid send window start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("invalid send window end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("send window start and end must differ")
+	}
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("unknown send window timezone: %s", w.Timezone)
+		}
+	}
+	return nil
+}
+
+// Next returns t when it falls inside the window, or else the moment the
+// window next opens.
+func (w SendWindow) Next(t time.Time, loc *time.Location) time.Time {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return t
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return t
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start < end && minute >= start && minute < end {
+		return t
+	}
+	if start > end && (minute >= start || minute < end) {
+		return t
+	}
+
+	opens := time.Date(local.Year(), local.Month(), local.Day(), start/60, start%60, 0, 0, loc)
+	if !opens.After(local) {
+		opens = time.Date(local.Year(), local.Month(), local.Day()+1, start/60, start%60, 0, 0, loc)
+	}
+	return opens
+}
+
+// location returns the member's timezone, falling back to the one of the
+// window and then to UTC
+func (w SendWindow) location(data map[string]interface{}) *time.Location {
+	if name, ok := utils.ResolvePath(data, memberTimezonePath).(string); ok && name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	if w.Timezone != "" {
+		if loc, err := time.LoadLocation(w.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// parseClock parses an "HH:MM" time of day into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Schedule computes when the action runs for a webhook received at now.
+//
+// Without an anchor the action runs after the Son delay plus its own. With
+// one, its delay counts from the timestamp at the anchor path instead and
+// may be negative, e.g. "-1h" with post.published_at. A time already past
+// runs right away. The send window then pushes the time to when it opens.
+func (a Action) Schedule(now time.Time, sonDelay time.Duration, data map[string]interface{}) (time.Time, error) {
+	delay, err := a.GetParsedDelay()
+	if err != nil {
+		return now, err
+	}
+
+	at := now.Add(sonDelay + delay)
+	if a.Anchor != "" {
+		anchor, err := resolveAnchor(data, a.Anchor)
+		if err != nil {
+			return now, err
+		}
+		at = anchor.Add(delay)
+	}
+	if at.Before(now) {
+		at = now
+	}
+
+	if a.SendWindow != nil {
+		at = a.SendWindow.Next(at, a.SendWindow.location(data))
+	}
+	return at, nil
+}
+
+// Deferred reports whether the action may run later than the webhook that
+// queued it
+func (a Action) Deferred(sonDelay time.Duration) bool {
+	delay, _ := a.GetParsedDelay()
+	return a.Anchor != "" || a.SendWindow != nil || sonDelay+delay > 0
+}
+
+// resolveAnchor reads the timestamp at an anchor path. member.created_at is
+// short for member.current.created_at, falling back to the previous version
+// for deletions.
+func resolveAnchor(data map[string]interface{}, path string) (time.Time, error) {
+	candidates := []string{path}
+	if resource, field, ok := strings.Cut(path, "."); ok && !strings.HasPrefix(field, "current.") && !strings.HasPrefix(field, "previous.") {
+		candidates = append(candidates, resource+".current."+field, resource+".previous."+field)
+	}
+
+	for _, candidate := range candidates {
+		switch v := utils.ResolvePath(data, candidate).(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("anchor %s is not a timestamp: %q", path, v)
+			}
+			return t, nil
+		case float64:
+			return time.Unix(int64(v), 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("anchor %s is missing from the payload", path)
+}
+
+// validateAnchor checks the anchor resolves to a timestamp in the sample
+// payload of the trigger
+func validateAnchor(path string, trigger TriggerType) error {
+	def, ok := GetTriggerDefinition(trigger)
+	if !ok {
+		return nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(def.SamplePayload, &data); err != nil {
+		return err
+	}
+	_, err := resolveAnchor(data, path)
+	return err
+}
@@ -0,0 +1,223 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type AttributeType string
+
+const (
+	AttributeAuto      AttributeType = ""
+	AttributeString    AttributeType = "string"
+	AttributeNumber    AttributeType = "number"
+	AttributeBoolean   AttributeType = "boolean"
+	AttributeDate      AttributeType = "date"
+	AttributeTimestamp AttributeType = "timestamp"
+	AttributeList      AttributeType = "list"
+)
+
+// AttributeMapping copies a Ghost member field into a Listmonk subscriber
+// attribute.
+//
+// Source is a dotted path into the current member (status, note, created_at,
+// labels, newsletters.name, geolocation.country...). List fields such as
+// labels map to the names of their items unless a sub-field is given. Type
+// converts the value, and Default is used when the field is missing or cannot
+// be converted.
+type AttributeMapping struct {
+	Source    string        `json:"source"`
+	Attribute string        `json:"attribute"`
+	Type      AttributeType `json:"type,omitempty"`
+	Default   interface{}   `json:"default,omitempty"`
+}
+
+// DefaultAttributeMappings reproduce the attributes manage_subscriber set
+// before mappings were configurable. They apply when an action has none.
+var DefaultAttributeMappings = []AttributeMapping{
+	{Source: "geolocation.city", Attribute: "city"},
+	{Source: "geolocation.country", Attribute: "country"},
+	{Source: "geolocation.latitude", Attribute: "latitude"},
+	{Source: "geolocation.longitude", Attribute: "longitude"},
+	{Source: "geolocation.timezone", Attribute: "timezone"},
+}
+
+func (m AttributeMapping) Validate() error {
+	if m.Source == "" {
+		return fmt.Errorf("attribute mapping is missing a source")
+	}
+	if m.Attribute == "" {
+		return fmt.Errorf("attribute mapping for %s is missing an attribute", m.Source)
+	}
+	switch m.Type {
+	case AttributeAuto, AttributeString, AttributeNumber, AttributeBoolean, AttributeDate, AttributeTimestamp, AttributeList:
+	default:
+		return fmt.Errorf("unknown attribute type %q for %s", m.Type, m.Attribute)
+	}
+	if m.Default != nil {
+		if _, err := m.convert(m.Default); err != nil {
+			return fmt.Errorf("invalid default for %s: %v", m.Attribute, err)
+		}
+	}
+	return nil
+}
+
+// ParseAttributeMappings decodes the attribute_mappings action parameter
+func ParseAttributeMappings(param interface{}) ([]AttributeMapping, error) {
+	if param == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attribute_mappings: %v", err)
+	}
+
+	var mappings []AttributeMapping
+	if err := json.Unmarshal(raw, &mappings); err != nil {
+		return nil, fmt.Errorf("invalid attribute_mappings: %v", err)
+	}
+
+	for _, mapping := range mappings {
+		if err := mapping.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+// MapAttributes builds Listmonk attributes from a member. Fields that are
+// missing and have no default are left out, and conversion failures are
+// returned alongside the attributes that could be mapped.
+func MapAttributes(mappings []AttributeMapping, member map[string]interface{}) (map[string]interface{}, []error) {
+	attributes := make(map[string]interface{})
+	var errs []error
+
+	for _, mapping := range mappings {
+		value := utils.ResolvePath(member, mapping.Source)
+		if isEmptyValue(value) {
+			if mapping.Default != nil {
+				attributes[mapping.Attribute], _ = mapping.convert(mapping.Default)
+			}
+			continue
+		}
+
+		converted, err := mapping.convert(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("attribute %s: %v", mapping.Attribute, err))
+			if mapping.Default != nil {
+				attributes[mapping.Attribute], _ = mapping.convert(mapping.Default)
+			}
+			continue
+		}
+		attributes[mapping.Attribute] = converted
+	}
+
+	return attributes, errs
+}
+
+func (m AttributeMapping) convert(value interface{}) (interface{}, error) {
+	value = itemNames(value)
+
+	switch m.Type {
+	case AttributeAuto:
+		return value, nil
+	case AttributeString:
+		if list, ok := value.([]interface{}); ok {
+			parts := make([]string, 0, len(list))
+			for _, item := range list {
+				parts = append(parts, stringify(item))
+			}
+			return strings.Join(parts, ","), nil
+		}
+		return stringify(value), nil
+	case AttributeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case bool:
+			if v {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		case []interface{}:
+			return float64(len(v)), nil
+		}
+	case AttributeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			return strconv.ParseBool(strings.TrimSpace(v))
+		case []interface{}:
+			return len(v) > 0, nil
+		}
+	case AttributeDate, AttributeTimestamp:
+		var t time.Time
+		switch v := value.(type) {
+		case string:
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date %q", v)
+			}
+			t = parsed
+		case float64:
+			t = time.Unix(int64(v), 0)
+		default:
+			return nil, fmt.Errorf("cannot convert %T to a date", value)
+		}
+		if m.Type == AttributeTimestamp {
+			return t.Unix(), nil
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	case AttributeList:
+		if list, ok := value.([]interface{}); ok {
+			return list, nil
+		}
+		return []interface{}{value}, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to %s", value, m.Type)
+}
+
+// itemNames replaces lists of objects (labels, newsletters, tiers) with the
+// names of their items.
+func itemNames(value interface{}) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	names := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(map[string]interface{}); ok {
+			name, _ := obj["name"].(string)
+			if name == "" {
+				name = itemKey(obj)
+			}
+			names = append(names, name)
+			continue
+		}
+		names = append(names, item)
+	}
+	return names
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	}
+	return false
+}
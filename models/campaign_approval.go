@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type CampaignApprovalStatus string
+
+const (
+	CampaignApprovalPending  CampaignApprovalStatus = "pending"
+	CampaignApprovalApproved CampaignApprovalStatus = "approved"
+	CampaignApprovalRejected CampaignApprovalStatus = "rejected"
+	CampaignApprovalExpired  CampaignApprovalStatus = "expired"
+	// Cancelled approvals belong to campaigns discarded because their post
+	// was unpublished or deleted
+	CampaignApprovalCancelled CampaignApprovalStatus = "cancelled"
+)
+
+// CampaignApproval tracks a campaign a create_campaign action left as a draft.
+// The campaign is only scheduled once someone approves it before ExpiresAt.
+type CampaignApproval struct {
+	ID                string                 `json:"id"`
+	UserID            string                 `json:"user_id"`
+	SonID             string                 `json:"son_id,omitempty"`
+	SonExecutionLogID string                 `json:"son_execution_log_id,omitempty"`
+	CampaignID        int                    `json:"campaign_id"`
+	CampaignName      string                 `json:"campaign_name"`
+	TestEmails        []string               `json:"test_emails"`
+	Status            CampaignApprovalStatus `json:"status"`
+	ExpiresAt         time.Time              `json:"expires_at"`
+	DecidedAt         *time.Time             `json:"decided_at,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
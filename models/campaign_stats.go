@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// CampaignStats holds the Listmonk counters of a campaign created by a Son,
+// refreshed in the background while the campaign is recent.
+type CampaignStats struct {
+	CampaignID   int        `json:"campaign_id"`
+	UserID       string     `json:"user_id"`
+	SonID        string     `json:"son_id,omitempty"`
+	SonName      string     `json:"son_name,omitempty"`
+	PostID       string     `json:"post_id,omitempty"`
+	PostTitle    string     `json:"post_title,omitempty"`
+	CampaignName string     `json:"campaign_name"`
+	Status       string     `json:"status"`
+	Sent         int        `json:"sent"`
+	Views        int        `json:"views"`
+	Clicks       int        `json:"clicks"`
+	Bounces      int        `json:"bounces"`
+	SyncedAt     *time.Time `json:"synced_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CampaignStatsSummary adds up the campaigns of a Son or of a post
+type CampaignStatsSummary struct {
+	SonID     string `json:"son_id,omitempty"`
+	PostID    string `json:"post_id,omitempty"`
+	Name      string `json:"name"`
+	Campaigns int    `json:"campaigns"`
+	Sent      int    `json:"sent"`
+	Views     int    `json:"views"`
+	Clicks    int    `json:"clicks"`
+	Bounces   int    `json:"bounces"`
+}
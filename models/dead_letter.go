@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is an action task archived after it ran out of retries or
+// failed for good. It is kept until it is retried or discarded.
+type DeadLetter struct {
+	ID           string          `json:"id"`
+	Queue        string          `json:"queue"`
+	ActionType   string          `json:"action_type"`
+	ActionIndex  *int            `json:"action_index,omitempty"`
+	SonID        string          `json:"son_id"`
+	SonName      string          `json:"son_name,omitempty"`
+	ExecutionID  string          `json:"execution_id"`
+	Payload      json.RawMessage `json:"payload"`
+	LastError    string          `json:"last_error"`
+	LastFailedAt time.Time       `json:"last_failed_at"`
+	Retried      int             `json:"retried"`
+	MaxRetry     int             `json:"max_retry"`
+}
@@ -0,0 +1,80 @@
+package models
+
+// ExecutionState is the state of an action of an execution, or of the
+// execution as a whole
+type ExecutionState string
+
+const (
+	// Pending executions have not queued their actions yet
+	StatePending   ExecutionState = "pending"
+	StateQueued    ExecutionState = "queued"
+	StateRunning   ExecutionState = "running"
+	StateSucceeded ExecutionState = "succeeded"
+	StateFailed    ExecutionState = "failed"
+	// Partially failed executions have actions that succeeded and actions
+	// that failed. Actions are never in this state.
+	StatePartiallyFailed ExecutionState = "partially_failed"
+	// Cancelled actions were dropped by an exit condition before they ran
+	StateCancelled ExecutionState = "cancelled"
+	// Skipped executions matched the trigger but not the conditions
+	StateSkipped ExecutionState = "skipped"
+)
+
+// actionTransitions lists the states an action can move to from each state.
+// A running action goes back to queued when it fails and is retried, and a
+// failed one when its dead letter is retried by hand.
+var actionTransitions = map[ExecutionState][]ExecutionState{
+	StatePending: {StateQueued, StateRunning, StateFailed, StateCancelled, StateSkipped},
+	StateQueued:  {StateRunning, StateFailed, StateCancelled},
+	StateRunning: {StateQueued, StateSucceeded, StateFailed, StateCancelled},
+	StateFailed:  {StateQueued},
+}
+
+// CanTransitionTo reports whether an action can move from s to next
+func (s ExecutionState) CanTransitionTo(next ExecutionState) bool {
+	for _, allowed := range actionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether an action in this state will not run again on its
+// own. Failed actions only run again when retried by hand.
+func (s ExecutionState) IsFinal() bool {
+	switch s {
+	case StateSucceeded, StateFailed, StatePartiallyFailed, StateCancelled, StateSkipped:
+		return true
+	}
+	return false
+}
+
+// AggregateExecutionState computes the state of an execution from the states
+// of its actions. An execution is running while any action runs, queued
+// while any waits to run, and otherwise settles on the outcome of its
+// actions: cancelled and skipped actions do not count as failures.
+func AggregateExecutionState(actions []ExecutionState) ExecutionState {
+	counts := make(map[ExecutionState]int)
+	for _, state := range actions {
+		counts[state]++
+	}
+
+	switch {
+	case counts[StateRunning] > 0:
+		return StateRunning
+	case counts[StateQueued] > 0:
+		return StateQueued
+	case counts[StatePending] > 0:
+		return StatePending
+	case counts[StateFailed] > 0 && counts[StateSucceeded] > 0:
+		return StatePartiallyFailed
+	case counts[StateFailed] > 0:
+		return StateFailed
+	case counts[StateSucceeded] > 0 || len(actions) == 0:
+		return StateSucceeded
+	case counts[StateCancelled] > 0:
+		return StateCancelled
+	}
+	return StateSkipped
+}
@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestAggregateExecutionState(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []ExecutionState
+		want    ExecutionState
+	}{
+		{"no actions", nil, StateSucceeded},
+		{"all succeeded", []ExecutionState{StateSucceeded, StateSucceeded}, StateSucceeded},
+		{"running wins", []ExecutionState{StateSucceeded, StateQueued, StateRunning}, StateRunning},
+		{"waiting on a later step", []ExecutionState{StateSucceeded, StateQueued}, StateQueued},
+		{"not queued yet", []ExecutionState{StatePending, StateFailed}, StatePending},
+		{"some failed", []ExecutionState{StateSucceeded, StateFailed}, StatePartiallyFailed},
+		{"all failed", []ExecutionState{StateFailed, StateFailed}, StateFailed},
+		{"failed and cancelled", []ExecutionState{StateFailed, StateCancelled}, StateFailed},
+		{"cancelled after a success", []ExecutionState{StateSucceeded, StateCancelled}, StateSucceeded},
+		{"all cancelled", []ExecutionState{StateCancelled, StateCancelled}, StateCancelled},
+		{"cancelled and skipped", []ExecutionState{StateSkipped, StateCancelled}, StateCancelled},
+		{"all skipped", []ExecutionState{StateSkipped}, StateSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AggregateExecutionState(tt.actions); got != tt.want {
+				t.Errorf("AggregateExecutionState(%v) = %s, want %s", tt.actions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutionStateTransitions(t *testing.T) {
+	tests := []struct {
+		from, to ExecutionState
+		want     bool
+	}{
+		{StateQueued, StateRunning, true},
+		{StateRunning, StateSucceeded, true},
+		{StateRunning, StateQueued, true},
+		{StateRunning, StateCancelled, true},
+		{StateFailed, StateQueued, true},
+		{StateFailed, StateRunning, false},
+		{StateSucceeded, StateFailed, false},
+		{StateCancelled, StateRunning, false},
+		{StateQueued, StateSucceeded, false},
+		{StateQueued, StatePartiallyFailed, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
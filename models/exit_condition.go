@@ -0,0 +1,64 @@
+package models
+
+import "fmt"
+
+// ExitCondition ends a member's sequence: when it fires, the steps of the
+// Son still waiting to run for the member are cancelled.
+type ExitCondition string
+
+const (
+	// ExitMemberDeleted fires when the member is deleted in Ghost
+	ExitMemberDeleted ExitCondition = "member_deleted"
+	// ExitMemberPaid fires when the member upgrades to a paid or comped plan
+	ExitMemberPaid ExitCondition = "member_paid"
+	// ExitMemberUnsubscribed fires when the member drops every newsletter in
+	// Ghost, or is unsubscribed from every list or blocklisted in Listmonk
+	ExitMemberUnsubscribed ExitCondition = "member_unsubscribed"
+)
+
+var exitConditionReasons = map[ExitCondition]string{
+	ExitMemberDeleted:      "member was deleted",
+	ExitMemberPaid:         "member upgraded to paid",
+	ExitMemberUnsubscribed: "member unsubscribed",
+}
+
+func (c ExitCondition) IsValid() bool {
+	_, ok := exitConditionReasons[c]
+	return ok
+}
+
+// Reason describes the exit, as recorded against the cancelled steps
+func (c ExitCondition) Reason() string {
+	return fmt.Sprintf("cancelled: %s", exitConditionReasons[c])
+}
+
+// MemberExits returns the exit conditions a member webhook fires. diff is the
+// member diff of member_updated webhooks.
+func MemberExits(trigger TriggerType, diff MemberDiff) []ExitCondition {
+	switch trigger {
+	case TriggerMemberDeleted:
+		return []ExitCondition{ExitMemberDeleted}
+	case TriggerMemberUpdated:
+		var exits []ExitCondition
+		if status, ok := diff["status"]; ok {
+			if current := stringify(status.Current); current == "paid" || current == "comped" {
+				exits = append(exits, ExitMemberPaid)
+			}
+		}
+		if newsletters, ok := diff["newsletters"]; ok && len(newsletters.Removed) > 0 && len(listItems(newsletters.Current)) == 0 {
+			exits = append(exits, ExitMemberUnsubscribed)
+		}
+		return exits
+	}
+	return nil
+}
+
+// ExitsOn reports whether the Son cancels its pending steps on the condition
+func (s *Son) ExitsOn(condition ExitCondition) bool {
+	for _, c := range s.ExitConditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type ListMappingSource string
+
+const (
+	ListMappingLabel      ListMappingSource = "label"
+	ListMappingNewsletter ListMappingSource = "newsletter"
+	ListMappingTier       ListMappingSource = "tier"
+)
+
+// listMappingFields maps each source type to the member field holding it
+var listMappingFields = map[ListMappingSource]string{
+	ListMappingLabel:      "labels",
+	ListMappingNewsletter: "newsletters",
+	ListMappingTier:       "tiers",
+}
+
+// ListMapping keeps members carrying a Ghost label, newsletter or tier
+// subscribed to a Listmonk list. SourceValue matches the item by id, slug or
+// name.
+type ListMapping struct {
+	ID          string            `json:"id"`
+	UserID      string            `json:"user_id"`
+	SourceType  ListMappingSource `json:"source_type"`
+	SourceValue string            `json:"source_value"`
+	ListID      int               `json:"list_id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+func (m ListMapping) Validate() error {
+	if _, ok := listMappingFields[m.SourceType]; !ok {
+		return fmt.Errorf("invalid source type: %s", m.SourceType)
+	}
+	if m.SourceValue == "" {
+		return fmt.Errorf("source value is required")
+	}
+	if m.ListID <= 0 {
+		return fmt.Errorf("invalid list id: %d", m.ListID)
+	}
+	return nil
+}
+
+// ResolveListChanges works out which lists a member has to be added to and
+// removed from. Members are added to the lists mapped from every label,
+// newsletter and tier they currently have, and removed from the lists mapped
+// from items they lost in this update, unless another item still maps to the
+// same list.
+func ResolveListChanges(mappings []ListMapping, webhookData map[string]interface{}) (add []int, remove []int) {
+	member, _ := webhookData["member"].(map[string]interface{})
+	current, _ := member["current"].(map[string]interface{})
+	diff := ComputeMemberDiff(webhookData)
+
+	keep := make(map[int]bool)
+	for _, mapping := range mappings {
+		field := listMappingFields[mapping.SourceType]
+		if containsItem(listItems(current[field]), mapping.SourceValue) && !keep[mapping.ListID] {
+			keep[mapping.ListID] = true
+			add = append(add, mapping.ListID)
+		}
+	}
+
+	removed := make(map[int]bool)
+	for _, mapping := range mappings {
+		field := listMappingFields[mapping.SourceType]
+		if keep[mapping.ListID] || removed[mapping.ListID] {
+			continue
+		}
+		if containsItem(diff[field].Removed, mapping.SourceValue) {
+			removed[mapping.ListID] = true
+			remove = append(remove, mapping.ListID)
+		}
+	}
+
+	return add, remove
+}
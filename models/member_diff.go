@@ -0,0 +1,184 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type FieldChangeType string
+
+const (
+	FieldChanged FieldChangeType = "changed"
+	FieldAdded   FieldChangeType = "added"
+	FieldRemoved FieldChangeType = "removed"
+)
+
+// listFields are member fields Ghost sends as arrays of objects. Changes to
+// them are expressed as added and removed items rather than from/to values.
+var listFields = map[string]bool{
+	"labels":      true,
+	"newsletters": true,
+	"tiers":       true,
+}
+
+// FieldChange describes a member field change a Son is watching for.
+//
+// For scalar fields (status, email, name...) From and To optionally restrict
+// the previous and current values. For list fields (labels, newsletters,
+// tiers) Change selects additions or removals, and Value optionally restricts
+// the item by id, slug or name.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Change FieldChangeType `json:"change,omitempty"`
+	From   string          `json:"from,omitempty"`
+	To     string          `json:"to,omitempty"`
+	Value  string          `json:"value,omitempty"`
+}
+
+// FieldDiff is the change of a single member field between the previous and
+// current versions Ghost sent.
+type FieldDiff struct {
+	Previous interface{}              `json:"previous"`
+	Current  interface{}              `json:"current"`
+	Added    []map[string]interface{} `json:"added,omitempty"`
+	Removed  []map[string]interface{} `json:"removed,omitempty"`
+}
+
+// MemberDiff maps each changed member field to its diff.
+type MemberDiff map[string]FieldDiff
+
+func (c FieldChange) Validate() error {
+	if c.Field == "" {
+		return fmt.Errorf("field change is missing a field")
+	}
+	switch c.Change {
+	case "", FieldChanged:
+	case FieldAdded, FieldRemoved:
+		if !listFields[c.Field] {
+			return fmt.Errorf("change %q is only supported for labels, newsletters and tiers", c.Change)
+		}
+	default:
+		return fmt.Errorf("unknown field change type: %s", c.Change)
+	}
+	if listFields[c.Field] && (c.From != "" || c.To != "") {
+		return fmt.Errorf("from/to cannot be used with list field %s", c.Field)
+	}
+	return nil
+}
+
+// ComputeMemberDiff builds the diff of a member.edited payload. Ghost only
+// includes the fields that changed in "previous", so those are the only
+// fields compared.
+func ComputeMemberDiff(webhookData map[string]interface{}) MemberDiff {
+	diff := MemberDiff{}
+
+	member, ok := webhookData["member"].(map[string]interface{})
+	if !ok {
+		return diff
+	}
+	current, _ := member["current"].(map[string]interface{})
+	previous, _ := member["previous"].(map[string]interface{})
+
+	for field, previousValue := range previous {
+		currentValue := current[field]
+		fieldDiff := FieldDiff{Previous: previousValue, Current: currentValue}
+
+		if listFields[field] {
+			previousItems := listItems(previousValue)
+			currentItems := listItems(currentValue)
+			fieldDiff.Added = itemsMissingFrom(currentItems, previousItems)
+			fieldDiff.Removed = itemsMissingFrom(previousItems, currentItems)
+			if len(fieldDiff.Added) == 0 && len(fieldDiff.Removed) == 0 {
+				continue
+			}
+		} else if stringify(previousValue) == stringify(currentValue) {
+			continue
+		}
+
+		diff[field] = fieldDiff
+	}
+
+	return diff
+}
+
+// Matches reports whether the diff contains the watched change.
+func (c FieldChange) Matches(diff MemberDiff) bool {
+	fieldDiff, ok := diff[c.Field]
+	if !ok {
+		return false
+	}
+
+	switch c.Change {
+	case FieldAdded:
+		return containsItem(fieldDiff.Added, c.Value)
+	case FieldRemoved:
+		return containsItem(fieldDiff.Removed, c.Value)
+	}
+
+	if listFields[c.Field] {
+		return true
+	}
+	if c.From != "" && !strings.EqualFold(stringify(fieldDiff.Previous), c.From) {
+		return false
+	}
+	if c.To != "" && !strings.EqualFold(stringify(fieldDiff.Current), c.To) {
+		return false
+	}
+	return true
+}
+
+func listItems(value interface{}) []map[string]interface{} {
+	raw, _ := value.([]interface{})
+	items := make([]map[string]interface{}, 0, len(raw))
+	for _, item := range raw {
+		if m, ok := item.(map[string]interface{}); ok {
+			items = append(items, m)
+		}
+	}
+	return items
+}
+
+func itemKey(item map[string]interface{}) string {
+	for _, key := range []string{"id", "slug", "name"} {
+		if v, ok := item[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return stringify(item)
+}
+
+func itemsMissingFrom(items, other []map[string]interface{}) []map[string]interface{} {
+	seen := make(map[string]bool, len(other))
+	for _, item := range other {
+		seen[itemKey(item)] = true
+	}
+
+	var missing []map[string]interface{}
+	for _, item := range items {
+		if !seen[itemKey(item)] {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+func containsItem(items []map[string]interface{}, value string) bool {
+	if value == "" {
+		return len(items) > 0
+	}
+	for _, item := range items {
+		for _, key := range []string{"id", "slug", "name"} {
+			if v, ok := item[key].(string); ok && strings.EqualFold(v, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringify(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
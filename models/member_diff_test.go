@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func memberPayload(t *testing.T, payload string) map[string]interface{} {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestComputeMemberDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    MemberDiff
+	}{
+		{
+			name:    "no member",
+			payload: `{"post": {"current": {}}}`,
+			want:    MemberDiff{},
+		},
+		{
+			name:    "scalar change",
+			payload: `{"member": {"current": {"status": "paid", "email": "a@example.com"}, "previous": {"status": "free"}}}`,
+			want:    MemberDiff{"status": {Previous: "free", Current: "paid"}},
+		},
+		{
+			name:    "unchanged scalar is left out",
+			payload: `{"member": {"current": {"name": "Jamie"}, "previous": {"name": "Jamie"}}}`,
+			want:    MemberDiff{},
+		},
+		{
+			name:    "field cleared",
+			payload: `{"member": {"current": {}, "previous": {"note": "vip"}}}`,
+			want:    MemberDiff{"note": {Previous: "vip", Current: nil}},
+		},
+		{
+			name: "labels added and removed",
+			payload: `{"member": {
+				"current": {"labels": [{"id": "1", "slug": "vip"}, {"id": "3", "slug": "beta"}]},
+				"previous": {"labels": [{"id": "1", "slug": "vip"}, {"id": "2", "slug": "trial"}]}
+			}}`,
+			want: MemberDiff{"labels": {
+				Previous: []interface{}{
+					map[string]interface{}{"id": "1", "slug": "vip"},
+					map[string]interface{}{"id": "2", "slug": "trial"},
+				},
+				Current: []interface{}{
+					map[string]interface{}{"id": "1", "slug": "vip"},
+					map[string]interface{}{"id": "3", "slug": "beta"},
+				},
+				Added:   []map[string]interface{}{{"id": "3", "slug": "beta"}},
+				Removed: []map[string]interface{}{{"id": "2", "slug": "trial"}},
+			}},
+		},
+		{
+			name: "reordered list is unchanged",
+			payload: `{"member": {
+				"current": {"newsletters": [{"id": "b"}, {"id": "a"}]},
+				"previous": {"newsletters": [{"id": "a"}, {"id": "b"}]}
+			}}`,
+			want: MemberDiff{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeMemberDiff(memberPayload(t, tt.payload))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComputeMemberDiff() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldChangeMatches(t *testing.T) {
+	diff := ComputeMemberDiff(memberPayload(t, `{"member": {
+		"current": {"status": "paid", "labels": [{"id": "1", "name": "VIP", "slug": "vip"}]},
+		"previous": {"status": "free", "labels": []}
+	}}`))
+
+	tests := []struct {
+		name   string
+		change FieldChange
+		want   bool
+	}{
+		{"any change", FieldChange{Field: "status"}, true},
+		{"from and to", FieldChange{Field: "status", From: "free", To: "paid"}, true},
+		{"to is case insensitive", FieldChange{Field: "status", To: "PAID"}, true},
+		{"other from", FieldChange{Field: "status", From: "comped"}, false},
+		{"unchanged field", FieldChange{Field: "email"}, false},
+		{"label added", FieldChange{Field: "labels", Change: FieldAdded}, true},
+		{"label added by name", FieldChange{Field: "labels", Change: FieldAdded, Value: "vip"}, true},
+		{"other label added", FieldChange{Field: "labels", Change: FieldAdded, Value: "beta"}, false},
+		{"label removed", FieldChange{Field: "labels", Change: FieldRemoved}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Matches(diff); got != tt.want {
+				t.Errorf("Matches(%+v) = %v, want %v", tt.change, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PostCampaign links a Ghost post to the Listmonk campaign a Son's
+// create_campaign action made for it, so the post never gets a second one.
+type PostCampaign struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	SonID       string    `json:"son_id"`
+	ActionIndex int       `json:"action_index"`
+	PostID      string    `json:"post_id"`
+	CampaignID  int       `json:"campaign_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type BackoffStrategy string
+
+const (
+	// BackoffExponential doubles the delay after every retry
+	BackoffExponential BackoffStrategy = "exponential"
+	// BackoffLinear adds the initial delay after every retry
+	BackoffLinear BackoffStrategy = "linear"
+	// BackoffFixed waits the same delay before every retry
+	BackoffFixed BackoffStrategy = "fixed"
+)
+
+const (
+	// DefaultMaxRetries is how often an action without a retry policy is
+	// retried before it is dead-lettered
+	DefaultMaxRetries = 3
+	maxRetriesLimit   = 25
+
+	defaultBackoffDelay = 30 * time.Second
+	maxBackoffDelay     = 24 * time.Hour
+)
+
+// RetryPolicy configures how a failing action is retried. Fields left empty
+// keep the queue defaults: 3 retries, an exponential backoff with jitter and
+// a 30 minute timeout per attempt.
+type RetryPolicy struct {
+	MaxRetries *int            `json:"max_retries,omitempty"`
+	Backoff    BackoffStrategy `json:"backoff,omitempty"`
+	// BackoffDelay is the delay before the first retry when Backoff is set,
+	// 30s by default
+	BackoffDelay string `json:"backoff_delay,omitempty"`
+	// Timeout bounds a single attempt
+	Timeout string `json:"timeout,omitempty"`
+}
+
+func (p RetryPolicy) Validate() error {
+	if p.MaxRetries != nil && (*p.MaxRetries < 0 || *p.MaxRetries > maxRetriesLimit) {
+		return fmt.Errorf("max_retries must be between 0 and %d", maxRetriesLimit)
+	}
+	switch p.Backoff {
+	case "", BackoffExponential, BackoffLinear, BackoffFixed:
+	default:
+		return fmt.Errorf("unknown backoff: %s", p.Backoff)
+	}
+	if p.BackoffDelay != "" {
+		if d, err := utils.ParseDuration(p.BackoffDelay); err != nil || d <= 0 {
+			return fmt.Errorf("invalid backoff_delay: %s", p.BackoffDelay)
+		}
+	}
+	if p.Timeout != "" {
+		if d, err := utils.ParseDuration(p.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout: %s", p.Timeout)
+		}
+	}
+	return nil
+}
+
+// RetryDelay returns the delay before the retry following n earlier ones.
+// It returns false when the policy keeps the default backoff.
+func (p RetryPolicy) RetryDelay(n int) (time.Duration, bool) {
+	if p.Backoff == "" {
+		return 0, false
+	}
+
+	base := defaultBackoffDelay
+	if d, err := utils.ParseDuration(p.BackoffDelay); err == nil && d > 0 {
+		base = d
+	}
+
+	delay := base
+	switch p.Backoff {
+	case BackoffExponential:
+		for i := 0; i < n && delay < maxBackoffDelay; i++ {
+			delay *= 2
+		}
+	case BackoffLinear:
+		delay = base * time.Duration(n+1)
+	}
+	if delay > maxBackoffDelay {
+		delay = maxBackoffDelay
+	}
+	return delay, true
+}
+
+// MaxRetries returns how often the action is retried before it is
+// dead-lettered
+func (a Action) MaxRetries() int {
+	if a.Retry != nil && a.Retry.MaxRetries != nil {
+		return *a.Retry.MaxRetries
+	}
+	return DefaultMaxRetries
+}
+
+// Timeout returns how long an attempt of the action may take, zero for the
+// queue default
+func (a Action) Timeout() time.Duration {
+	if a.Retry == nil || a.Retry.Timeout == "" {
+		return 0
+	}
+	d, err := utils.ParseDuration(a.Retry.Timeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
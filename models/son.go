@@ -2,29 +2,12 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/troneras/ghost-listmonk-connector/utils"
 )
 
-type TriggerType string
-type ActionType string
-
-const (
-	TriggerMemberCreated TriggerType = "member_created"
-	TriggerMemberDeleted TriggerType = "member_deleted"
-	TriggerMemberUpdated TriggerType = "member_updated"
-	TriggerPagePublished TriggerType = "page_published"
-	TriggerPostPublished TriggerType = "post_published"
-	TriggerPostScheduled TriggerType = "post_scheduled"
-)
-
-const (
-	ActionSendTransactionalEmail ActionType = "send_transactional_email"
-	ActionManageSubscriber       ActionType = "manage_subscriber"
-	ActionCreateCampaign         ActionType = "create_campaign"
-)
-
 type Son struct {
 	ID        string      `json:"id"`
 	UserID    string      `json:"user_id"`
@@ -35,11 +18,18 @@ type Son struct {
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 	Enabled   bool        `json:"enabled"`
-}
 
-type Action struct {
-	Type       ActionType     `json:"type"`
-	Parameters map[string]any `json:"parameters"`
+	// FieldChanges restricts member_updated Sons to specific field changes.
+	// The Son runs when any of them matches; an empty list matches any update.
+	FieldChanges []FieldChange `json:"field_changes,omitempty"`
+
+	// Conditions are expressions (see utils.ParseCondition) evaluated against
+	// the webhook data. All of them must hold for the Son to run.
+	Conditions []string `json:"conditions,omitempty"`
+
+	// ExitConditions cancel the steps still queued for a member by earlier
+	// executions of the Son when they fire, e.g. once the member upgrades.
+	ExitConditions []ExitCondition `json:"exit_conditions,omitempty"`
 }
 
 //
@@ -70,6 +60,88 @@ func (s Son) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Validate checks the Son configuration before it is saved.
+func (s *Son) Validate() error {
+	if !s.Trigger.IsValid() {
+		return fmt.Errorf("unknown trigger: %s", s.Trigger)
+	}
+
+	for i, action := range s.Actions {
+		if err := action.Validate(); err != nil {
+			return err
+		}
+		if action.Anchor != "" {
+			if err := validateAnchor(action.Anchor, s.Trigger); err != nil {
+				return fmt.Errorf("action %d: %w", i+1, err)
+			}
+		}
+		if action.Type == ActionCreateCampaign {
+			if err := validateCampaignTemplates(action.Parameters, s.TemplateSon()); err != nil {
+				return fmt.Errorf("action %d: %w", i+1, err)
+			}
+		}
+	}
+
+	if len(s.FieldChanges) > 0 && s.Trigger != TriggerMemberUpdated {
+		return fmt.Errorf("field changes can only be used with the %s trigger", TriggerMemberUpdated)
+	}
+	for _, change := range s.FieldChanges {
+		if err := change.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, condition := range s.Conditions {
+		if _, err := utils.ParseCondition(condition); err != nil {
+			return fmt.Errorf("invalid condition %q: %w", condition, err)
+		}
+	}
+
+	for _, exit := range s.ExitConditions {
+		if !exit.IsValid() {
+			return fmt.Errorf("unknown exit condition: %s", exit)
+		}
+		if !s.Trigger.IsMemberTrigger() {
+			return fmt.Errorf("exit conditions can only be used with member triggers")
+		}
+	}
+
+	return nil
+}
+
+// EvaluateConditions checks the Son conditions against the webhook data. When
+// a condition does not hold it returns false with the reason.
+func (s *Son) EvaluateConditions(data map[string]interface{}) (bool, string) {
+	for _, source := range s.Conditions {
+		condition, err := utils.ParseCondition(source)
+		if err != nil {
+			return false, fmt.Sprintf("invalid condition %q: %v", source, err)
+		}
+		if !condition.Evaluate(data) {
+			return false, fmt.Sprintf("condition not met: %s", source)
+		}
+	}
+	return true, ""
+}
+
+// MatchesMemberDiff reports whether a member update should run this Son.
+func (s *Son) MatchesMemberDiff(diff MemberDiff) bool {
+	if len(s.FieldChanges) == 0 {
+		return true
+	}
+	for _, change := range s.FieldChanges {
+		if change.Matches(diff) {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateSon returns the Son fields available to templates
+func (s *Son) TemplateSon() TemplateSon {
+	return TemplateSon{ID: s.ID, Name: s.Name, Trigger: s.Trigger}
+}
+
 func (s *Son) GetParsedDelay() (time.Duration, error) {
 	return utils.ParseDuration(s.Delay)
 }
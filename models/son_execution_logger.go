@@ -6,19 +6,41 @@ import (
 )
 
 type SonExecutionLog struct {
-	ID           string    `json:"id"`
-	SonID        string    `json:"son_id"`
-	WebhookLogID string    `json:"webhook_log_id"`
-	Status       string    `json:"status"`
-	ExecutedAt   time.Time `json:"executed_at"`
-	ErrorMessage string    `json:"error_message"`
+	ID           string         `json:"id"`
+	SonID        string         `json:"son_id"`
+	WebhookLogID string         `json:"webhook_log_id"`
+	MemberEmail  string         `json:"member_email,omitempty"`
+	Status       ExecutionState `json:"status"`
+	ExecutedAt   time.Time      `json:"executed_at"`
+	ErrorMessage string         `json:"error_message"`
+	SkipReason   string         `json:"skip_reason,omitempty"`
 }
 
 type ActionExecutionLog struct {
-	ID             string    `json:"id"`
-	ExecutionLogID string    `json:"execution_log_id"`
-	ActionType     string    `json:"action_type"`
-	Status         string    `json:"status"`
-	ExecutedAt     time.Time `json:"executed_at"`
-	ErrorMessage   string    `json:"error_message"`
+	ID             string         `json:"id"`
+	ExecutionLogID string         `json:"execution_log_id"`
+	ActionType     string         `json:"action_type"`
+	Status         ExecutionState `json:"status"`
+	ExecutedAt     time.Time      `json:"executed_at"`
+	ErrorMessage   string         `json:"error_message"`
+	ResponseStatus *int           `json:"response_status,omitempty"`
+	ResponseBody   string         `json:"response_body,omitempty"`
+	CampaignID     *int           `json:"campaign_id,omitempty"`
+}
+
+// ExecutionStep is one action of an execution, scheduled at the Son delay
+// plus its own. The steps of an execution show how far a member has got
+// through the sequence.
+type ExecutionStep struct {
+	ID             string         `json:"id"`
+	ExecutionLogID string         `json:"execution_log_id"`
+	SonID          string         `json:"son_id,omitempty"`
+	ActionIndex    int            `json:"action_index"`
+	ActionType     string         `json:"action_type"`
+	TaskID         string         `json:"task_id,omitempty"`
+	Queue          string         `json:"queue"`
+	Status         ExecutionState `json:"status"`
+	ScheduledFor   time.Time      `json:"scheduled_for"`
+	FinishedAt     *time.Time     `json:"finished_at,omitempty"`
+	ErrorMessage   string         `json:"error_message,omitempty"`
 }
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// TemplateContext is what campaign templates are rendered with, e.g.
+// {{ .Post.Title }}, {{ .Site.URL }} or {{ .Son.Name }}. Every field has a
+// zero value, so a payload missing a field renders it empty.
+type TemplateContext struct {
+	Post TemplatePost
+	Site TemplateSite
+	Son  TemplateSon
+}
+
+type TemplatePost struct {
+	ID            string
+	UUID          string
+	Title         string
+	Slug          string
+	URL           string
+	Html          template.HTML
+	PlainText     string
+	Excerpt       string
+	CustomExcerpt string
+	FeatureImage  string
+	Featured      bool
+	Status        string
+	Visibility    string
+	ReadingTime   int
+	// Dates are RFC 3339 strings, use the date function to format them
+	PublishedAt   string
+	UpdatedAt     string
+	CreatedAt     string
+	Tags          []TemplateTag
+	PrimaryTag    TemplateTag
+	Authors       []TemplateAuthor
+	PrimaryAuthor TemplateAuthor
+}
+
+type TemplateTag struct {
+	ID   string
+	Name string
+	Slug string
+	URL  string
+}
+
+type TemplateAuthor struct {
+	ID           string
+	Name         string
+	Slug         string
+	URL          string
+	ProfileImage string
+}
+
+type TemplateSite struct {
+	// URL is the root of the Ghost site, taken from the post URL
+	URL string
+}
+
+type TemplateSon struct {
+	ID      string
+	Name    string
+	Trigger TriggerType
+}
+
+// ghostPost mirrors the post and page fields of Ghost webhook payloads
+type ghostPost struct {
+	ID            string        `json:"id"`
+	UUID          string        `json:"uuid"`
+	Title         string        `json:"title"`
+	Slug          string        `json:"slug"`
+	URL           string        `json:"url"`
+	HTML          string        `json:"html"`
+	PlainText     string        `json:"plaintext"`
+	Excerpt       string        `json:"excerpt"`
+	CustomExcerpt string        `json:"custom_excerpt"`
+	FeatureImage  string        `json:"feature_image"`
+	Featured      bool          `json:"featured"`
+	Status        string        `json:"status"`
+	Visibility    string        `json:"visibility"`
+	ReadingTime   int           `json:"reading_time"`
+	PublishedAt   string        `json:"published_at"`
+	UpdatedAt     string        `json:"updated_at"`
+	CreatedAt     string        `json:"created_at"`
+	Tags          []ghostTag    `json:"tags"`
+	PrimaryTag    *ghostTag     `json:"primary_tag"`
+	Authors       []ghostAuthor `json:"authors"`
+	PrimaryAuthor *ghostAuthor  `json:"primary_author"`
+}
+
+type ghostTag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	URL  string `json:"url"`
+}
+
+type ghostAuthor struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	URL          string `json:"url"`
+	ProfileImage string `json:"profile_image"`
+}
+
+// NewTemplateContext builds the template context of a Son from the webhook
+// data. The current post is used, or the previous one for deletions.
+func NewTemplateContext(son TemplateSon, data map[string]interface{}) (TemplateContext, error) {
+	ctx := TemplateContext{Son: son}
+
+	resource, ok := data["post"].(map[string]interface{})
+	if !ok {
+		resource, _ = data["page"].(map[string]interface{})
+	}
+	if resource == nil {
+		return ctx, nil
+	}
+
+	post, ok := resource["current"].(map[string]interface{})
+	if id, _ := post["id"].(string); !ok || id == "" {
+		if previous, ok := resource["previous"].(map[string]interface{}); ok {
+			post = previous
+		}
+	}
+
+	raw, err := json.Marshal(post)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to marshal post: %w", err)
+	}
+	var p ghostPost
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return ctx, fmt.Errorf("invalid post data: %w", err)
+	}
+
+	ctx.Post = TemplatePost{
+		ID:            p.ID,
+		UUID:          p.UUID,
+		Title:         p.Title,
+		Slug:          p.Slug,
+		URL:           p.URL,
+		Html:          template.HTML(p.HTML),
+		PlainText:     p.PlainText,
+		Excerpt:       p.Excerpt,
+		CustomExcerpt: p.CustomExcerpt,
+		FeatureImage:  p.FeatureImage,
+		Featured:      p.Featured,
+		Status:        p.Status,
+		Visibility:    p.Visibility,
+		ReadingTime:   p.ReadingTime,
+		PublishedAt:   p.PublishedAt,
+		UpdatedAt:     p.UpdatedAt,
+		CreatedAt:     p.CreatedAt,
+	}
+	for _, tag := range p.Tags {
+		ctx.Post.Tags = append(ctx.Post.Tags, TemplateTag(tag))
+	}
+	if p.PrimaryTag != nil {
+		ctx.Post.PrimaryTag = TemplateTag(*p.PrimaryTag)
+	}
+	for _, author := range p.Authors {
+		ctx.Post.Authors = append(ctx.Post.Authors, TemplateAuthor(author))
+	}
+	if p.PrimaryAuthor != nil {
+		ctx.Post.PrimaryAuthor = TemplateAuthor(*p.PrimaryAuthor)
+	}
+
+	if u, err := url.Parse(p.URL); err == nil && u.Host != "" {
+		ctx.Site.URL = u.Scheme + "://" + u.Host + "/"
+	}
+
+	return ctx, nil
+}
+
+// RenderCampaign renders the subject and body templates of a create_campaign
+// action. The subject is plain text, the body is HTML.
+func RenderCampaign(params map[string]interface{}, ctx TemplateContext) (string, string, error) {
+	subject, _ := params["subject"].(string)
+	renderedSubject, err := utils.RenderTextTemplate("subject", subject, ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	body, ok := params["body"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("invalid body in parameters")
+	}
+	renderedBody, err := utils.RenderTemplate("body", body, ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return renderedSubject, renderedBody, nil
+}
+
+// validateCampaignTemplates renders the templates of a create_campaign
+// action against the sample payload of the trigger, which catches syntax
+// errors as well as references to fields the context does not have.
+func validateCampaignTemplates(params map[string]interface{}, son TemplateSon) error {
+	var data map[string]interface{}
+	if def, ok := GetTriggerDefinition(son.Trigger); ok {
+		if err := json.Unmarshal(def.SamplePayload, &data); err != nil {
+			return err
+		}
+	}
+
+	ctx, err := NewTemplateContext(son, data)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = RenderCampaign(params, ctx)
+	return err
+}
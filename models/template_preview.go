@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// TemplatePreview is the result of rendering a campaign against a payload.
+// Warnings list the fields the templates read that are empty in it.
+type TemplatePreview struct {
+	Subject  string                `json:"subject"`
+	Body     string                `json:"body"`
+	Warnings []utils.TemplateError `json:"warnings"`
+	Errors   []utils.TemplateError `json:"errors"`
+}
+
+// PreviewCampaign renders the subject and body of a campaign, collecting
+// errors instead of stopping at the first one.
+func PreviewCampaign(subject string, body string, ctx TemplateContext) TemplatePreview {
+	preview := TemplatePreview{
+		Warnings: []utils.TemplateError{},
+		Errors:   []utils.TemplateError{},
+	}
+
+	var err error
+	if preview.Subject, err = utils.RenderTextTemplate("subject", subject, ctx); err != nil {
+		preview.Errors = append(preview.Errors, utils.NewTemplateError("subject", err))
+	}
+	if preview.Body, err = utils.RenderTemplate("body", body, ctx); err != nil {
+		preview.Errors = append(preview.Errors, utils.NewTemplateError("body", err))
+	}
+
+	for _, source := range []struct{ name, text string }{{"subject", subject}, {"body", body}} {
+		fields, err := utils.TemplateFields(source.text)
+		if err != nil {
+			// Already reported as a parse error
+			continue
+		}
+		seen := map[string]bool{}
+		for _, field := range fields {
+			path := strings.Join(field.Path, ".")
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if value, ok := lookupField(reflect.ValueOf(ctx), field.Path); ok && isEmptyField(value) {
+				preview.Warnings = append(preview.Warnings, utils.TemplateError{
+					Template: source.name,
+					Line:     field.Line,
+					Message:  fmt.Sprintf(".%s is empty in this payload", path),
+				})
+			}
+		}
+	}
+
+	return preview
+}
+
+// lookupField follows a field path through structs and maps. Paths that do
+// not exist are execution errors, reported by the render itself.
+func lookupField(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, true
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(name)
+		case reflect.Map:
+			// A missing key renders empty rather than failing
+			if v = v.MapIndex(reflect.ValueOf(name)); !v.IsValid() {
+				return v, true
+			}
+		default:
+			return reflect.Value{}, false
+		}
+		if !v.IsValid() {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func isEmptyField(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		// false and 0 are meaningful values
+		return false
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+type TriggerType string
+
+const (
+	TriggerMemberCreated TriggerType = "member_created"
+	TriggerMemberDeleted TriggerType = "member_deleted"
+	TriggerMemberUpdated TriggerType = "member_updated"
+
+	TriggerPostPublished       TriggerType = "post_published"
+	TriggerPostPublishedEdited TriggerType = "post_published_edited"
+	TriggerPostScheduled       TriggerType = "post_scheduled"
+	TriggerPostUnpublished     TriggerType = "post_unpublished"
+	TriggerPostEdited          TriggerType = "post_edited"
+	TriggerPostDeleted         TriggerType = "post_deleted"
+
+	TriggerPagePublished       TriggerType = "page_published"
+	TriggerPagePublishedEdited TriggerType = "page_published_edited"
+	TriggerPageUnpublished     TriggerType = "page_unpublished"
+	TriggerPageEdited          TriggerType = "page_edited"
+	TriggerPageDeleted         TriggerType = "page_deleted"
+
+	TriggerTagAdded   TriggerType = "tag_added"
+	TriggerTagDeleted TriggerType = "tag_deleted"
+
+	TriggerSiteChanged TriggerType = "site_changed"
+)
+
+// TriggerDefinition describes a trigger Sons can subscribe to: its display
+// name, the Ghost webhook event that fires it and an example payload.
+type TriggerDefinition struct {
+	Type          TriggerType     `json:"type"`
+	Name          string          `json:"name"`
+	GhostEvent    string          `json:"ghost_event"`
+	SamplePayload json.RawMessage `json:"sample_payload"`
+}
+
+var triggerDefinitions = []TriggerDefinition{
+	{TriggerMemberCreated, "Member Created", "member.added", sampleMemberCreated},
+	{TriggerMemberUpdated, "Member Updated", "member.edited", sampleMemberUpdated},
+	{TriggerMemberDeleted, "Member Deleted", "member.deleted", sampleMemberDeleted},
+
+	{TriggerPostPublished, "Post Published", "post.published", samplePostPublished},
+	{TriggerPostPublishedEdited, "Published Post Edited", "post.published.edited", samplePostPublishedEdited},
+	{TriggerPostScheduled, "Post Scheduled", "post.scheduled", samplePostScheduled},
+	{TriggerPostUnpublished, "Post Unpublished", "post.unpublished", samplePostUnpublished},
+	{TriggerPostEdited, "Post Edited", "post.edited", samplePostEdited},
+	{TriggerPostDeleted, "Post Deleted", "post.deleted", samplePostDeleted},
+
+	{TriggerPagePublished, "Page Published", "page.published", samplePagePublished},
+	{TriggerPagePublishedEdited, "Published Page Edited", "page.published.edited", samplePagePublishedEdited},
+	{TriggerPageUnpublished, "Page Unpublished", "page.unpublished", samplePageUnpublished},
+	{TriggerPageEdited, "Page Edited", "page.edited", samplePageEdited},
+	{TriggerPageDeleted, "Page Deleted", "page.deleted", samplePageDeleted},
+
+	{TriggerTagAdded, "Tag Added", "tag.added", sampleTagAdded},
+	{TriggerTagDeleted, "Tag Deleted", "tag.deleted", sampleTagDeleted},
+
+	{TriggerSiteChanged, "Site Changed", "site.changed", sampleSiteChanged},
+}
+
+// TriggerDefinitions returns every trigger Sons can subscribe to.
+func TriggerDefinitions() []TriggerDefinition {
+	return triggerDefinitions
+}
+
+// GetTriggerDefinition looks up the definition of a trigger.
+func GetTriggerDefinition(t TriggerType) (TriggerDefinition, bool) {
+	for _, def := range triggerDefinitions {
+		if def.Type == t {
+			return def, true
+		}
+	}
+	return TriggerDefinition{}, false
+}
+
+func (t TriggerType) IsValid() bool {
+	_, ok := GetTriggerDefinition(t)
+	return ok
+}
+
+// IsMemberTrigger reports whether the trigger is about a member, whose
+// executions are tracked per member email.
+func (t TriggerType) IsMemberTrigger() bool {
+	return t == TriggerMemberCreated || t == TriggerMemberUpdated || t == TriggerMemberDeleted
+}
+
+// TriggerTypeFromGhostEvent resolves a Ghost webhook event name to its trigger.
+func TriggerTypeFromGhostEvent(event string) (TriggerType, error) {
+	for _, def := range triggerDefinitions {
+		if def.GhostEvent == event {
+			return def.Type, nil
+		}
+	}
+	return "", utils.NewError("UnsupportedGhostEvent", fmt.Sprintf("Unsupported Ghost event: %s", event))
+}
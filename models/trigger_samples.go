@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Sample payloads mirror what Ghost sends for each webhook event. They are
+// served to the UI so users can see which fields a trigger provides.
+
+const sampleMember = `{
+	"id": "6514a5c3f1d2a40001b2c3d4",
+	"uuid": "0f6b6a8e-3b8f-4a0e-9d1c-6a0c1d2e3f40",
+	"email": "jamie@example.com",
+	"name": "Jamie Larson",
+	"note": null,
+	"geolocation": "{\"city\":\"Madrid\",\"country\":\"Spain\",\"country_code\":\"ES\",\"latitude\":40.4163,\"longitude\":-3.6934,\"timezone\":\"Europe/Madrid\"}",
+	"subscribed": true,
+	"status": "free",
+	"labels": [{"id": "6514a5c3f1d2a40001b2c3e1", "name": "VIP", "slug": "vip"}],
+	"newsletters": [{"id": "6514a5c3f1d2a40001b2c3f0", "name": "Weekly Digest", "status": "active"}],
+	"tiers": [],
+	"email_disabled": false,
+	"created_at": "2024-09-01T10:00:00.000Z",
+	"updated_at": "2024-09-01T10:00:00.000Z"
+}`
+
+const samplePost = `{
+	"id": "6514a5c3f1d2a40001b2c400",
+	"uuid": "2a7c1e34-8f5b-4c2d-a1e9-7b3d5f6a8c90",
+	"title": "Welcome to Ghost",
+	"slug": "welcome",
+	"html": "<p>Hello from Ghost.</p>",
+	"plaintext": "Hello from Ghost.",
+	"feature_image": "https://example.com/content/images/welcome.jpg",
+	"featured": false,
+	"status": "published",
+	"visibility": "public",
+	"created_at": "2024-09-01T09:00:00.000Z",
+	"updated_at": "2024-09-01T10:00:00.000Z",
+	"published_at": "2024-09-01T10:00:00.000Z",
+	"custom_excerpt": "A short introduction.",
+	"excerpt": "A short introduction.",
+	"reading_time": 1,
+	"url": "https://example.com/welcome/",
+	"tags": [{"id": "6514a5c3f1d2a40001b2c410", "name": "newsletter", "slug": "newsletter"}],
+	"authors": [{"id": "1", "name": "Ghost", "slug": "ghost"}],
+	"primary_author": {"id": "1", "name": "Ghost", "slug": "ghost"},
+	"primary_tag": {"id": "6514a5c3f1d2a40001b2c410", "name": "newsletter", "slug": "newsletter"}
+}`
+
+const sampleTag = `{
+	"id": "6514a5c3f1d2a40001b2c410",
+	"name": "newsletter",
+	"slug": "newsletter",
+	"description": null,
+	"visibility": "public",
+	"created_at": "2024-09-01T09:00:00.000Z",
+	"updated_at": "2024-09-01T09:00:00.000Z"
+}`
+
+func withStatus(resource, status string) string {
+	return strings.Replace(resource, `"status": "published"`, `"status": "`+status+`"`, 1)
+}
+
+func samplePayload(resource, current, previous string) json.RawMessage {
+	return json.RawMessage(`{"` + resource + `": {"current": ` + current + `, "previous": ` + previous + `}}`)
+}
+
+var (
+	sampleMemberCreated = samplePayload("member", sampleMember, `{}`)
+	sampleMemberUpdated = samplePayload("member", sampleMember, `{"name": "Jamie", "labels": [], "updated_at": "2024-08-30T10:00:00.000Z"}`)
+	sampleMemberDeleted = samplePayload("member", `{}`, sampleMember)
+
+	samplePostPublished       = samplePayload("post", samplePost, `{"status": "draft", "published_at": null, "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePostPublishedEdited = samplePayload("post", samplePost, `{"title": "Welcome", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePostScheduled       = samplePayload("post", withStatus(samplePost, "scheduled"), `{"status": "draft", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePostUnpublished     = samplePayload("post", withStatus(samplePost, "draft"), `{"status": "published", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePostEdited          = samplePayload("post", withStatus(samplePost, "draft"), `{"title": "Welcome", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePostDeleted         = samplePayload("post", `{}`, samplePost)
+
+	samplePagePublished       = samplePayload("page", samplePost, `{"status": "draft", "published_at": null, "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePagePublishedEdited = samplePayload("page", samplePost, `{"title": "Welcome", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePageUnpublished     = samplePayload("page", withStatus(samplePost, "draft"), `{"status": "published", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePageEdited          = samplePayload("page", withStatus(samplePost, "draft"), `{"title": "Welcome", "updated_at": "2024-09-01T09:30:00.000Z"}`)
+	samplePageDeleted         = samplePayload("page", `{}`, samplePost)
+
+	sampleTagAdded   = samplePayload("tag", sampleTag, `{}`)
+	sampleTagDeleted = samplePayload("tag", `{}`, sampleTag)
+
+	sampleSiteChanged = json.RawMessage(`{}`)
+)
@@ -7,8 +7,24 @@ import (
 type Webhook struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
+	Label     string    `json:"label"`
 	Endpoint  string    `json:"endpoint"`
+	URL       string    `json:"url,omitempty"`
 	Secret    string    `json:"secret"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// After a rotation the previous secret keeps being accepted until
+	// PreviousSecretExpiresAt, so Ghost can be updated without dropping events.
+	PreviousSecret          string     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+}
+
+// ValidSecrets returns the secrets a signature may currently be made with
+func (w *Webhook) ValidSecrets(now time.Time) []string {
+	secrets := []string{w.Secret}
+	if w.PreviousSecret != "" && w.PreviousSecretExpiresAt != nil && now.Before(*w.PreviousSecretExpiresAt) {
+		secrets = append(secrets, w.PreviousSecret)
+	}
+	return secrets
 }
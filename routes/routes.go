@@ -28,12 +28,58 @@ func SetupRoutes(r *gin.Engine, handlers *handlers.Handlers, services *services.
 				sons.PUT("/:id", handlers.Son.Update)
 				sons.DELETE("/:id", handlers.Son.Delete)
 			}
+			protected.GET("/triggers", handlers.Trigger.List)
+			protected.GET("/actions", handlers.Action.List)
 			protected.GET("/son-execution-logs", handlers.SonExecutionLog.GetSonExecutionLogs)
 			protected.GET("/son-executions/:executionId/action-logs", handlers.SonExecutionLog.GetActionExecutionLogs)
+			protected.GET("/son-executions/:executionId/steps", handlers.SonExecutionLog.GetExecutionSteps)
 
 			protected.GET("/webhook-info", handlers.Webhook.GetWebhookInfo)
+
+			webhooks := protected.Group("/webhooks")
+			{
+				webhooks.GET("", handlers.Webhook.ListWebhooks)
+				webhooks.POST("", handlers.Webhook.CreateWebhook)
+				webhooks.PATCH("/:id", handlers.Webhook.UpdateWebhook)
+				webhooks.DELETE("/:id", handlers.Webhook.DeleteWebhook)
+				webhooks.POST("/:id/rotate-secret", handlers.Webhook.RotateWebhookSecret)
+			}
+
+			listMappings := protected.Group("/list-mappings")
+			{
+				listMappings.GET("", handlers.ListMapping.List)
+				listMappings.POST("", handlers.ListMapping.Create)
+				listMappings.PUT("/:id", handlers.ListMapping.Update)
+				listMappings.DELETE("/:id", handlers.ListMapping.Delete)
+			}
+
+			approvals := protected.Group("/campaign-approvals")
+			{
+				approvals.GET("", handlers.CampaignApproval.List)
+				approvals.POST("/:id/approve", handlers.CampaignApproval.Approve)
+				approvals.POST("/:id/reject", handlers.CampaignApproval.Reject)
+			}
+
+			campaignStats := protected.Group("/campaign-stats")
+			{
+				campaignStats.GET("/sons", handlers.CampaignStats.SummarizeBySon)
+				campaignStats.GET("/sons/:id", handlers.CampaignStats.ListBySon)
+				campaignStats.GET("/posts", handlers.CampaignStats.SummarizeByPost)
+				campaignStats.GET("/posts/:id", handlers.CampaignStats.ListByPost)
+			}
+
+			deadLetters := protected.Group("/dead-letters")
+			{
+				deadLetters.GET("", handlers.DeadLetter.List)
+				deadLetters.POST("/retry", handlers.DeadLetter.RetryMany)
+				deadLetters.POST("/discard", handlers.DeadLetter.DiscardMany)
+				deadLetters.POST("/:id/retry", handlers.DeadLetter.Retry)
+				deadLetters.DELETE("/:id", handlers.DeadLetter.Discard)
+			}
+
 			protected.GET("/lists", handlers.Listmonk.GetLists)
 			protected.GET("/templates", handlers.Listmonk.GetTemplates)
+			protected.POST("/template-preview", handlers.TemplatePreview.Preview)
 
 			// Webhook log routes
 			protected.GET("/webhook-logs", handlers.WebhookLog.GetLogs)
@@ -46,5 +92,6 @@ func SetupRoutes(r *gin.Engine, handlers *handlers.Handlers, services *services.
 	}
 
 	// Webhook route (public, but requires signature verification)
+	r.POST("/webhook/:endpoint/:event", handlers.Webhook.HandleWebhook)
 	r.POST("/webhook/:endpoint", handlers.Webhook.HandleWebhook)
 }
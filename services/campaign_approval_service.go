@@ -0,0 +1,269 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/database"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+var (
+	ErrCampaignApprovalNotFound   = errors.New("campaign approval not found")
+	ErrCampaignApprovalNotPending = errors.New("campaign approval is no longer pending")
+)
+
+const campaignApprovalColumns = "id, user_id, son_id, son_execution_log_id, campaign_id, campaign_name, test_emails, status, expires_at, decided_at, created_at, updated_at"
+
+// minimumSendDelay is how far in the future an approved campaign is scheduled
+// when its original send_at has already passed.
+const minimumSendDelay = 5 * time.Minute
+
+type CampaignApprovalService struct {
+	db             *sql.DB
+	listmonkClient *ListmonkClient
+}
+
+func NewCampaignApprovalService(listmonkClient *ListmonkClient) *CampaignApprovalService {
+	return &CampaignApprovalService{
+		db:             database.GetDB(),
+		listmonkClient: listmonkClient,
+	}
+}
+
+func (s *CampaignApprovalService) Create(approval *models.CampaignApproval) error {
+	now := time.Now()
+	approval.ID = utils.GenerateUUID()
+	approval.Status = models.CampaignApprovalPending
+	approval.CreatedAt = now
+	approval.UpdatedAt = now
+
+	testEmailsJSON, err := json.Marshal(approval.TestEmails)
+	if err != nil {
+		return fmt.Errorf("failed to marshal test emails: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO campaign_approvals (id, user_id, son_id, son_execution_log_id, campaign_id, campaign_name, test_emails, status, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, approval.ID, approval.UserID, nullString(approval.SonID), nullString(approval.SonExecutionLogID), approval.CampaignID, approval.CampaignName,
+		testEmailsJSON, approval.Status, approval.ExpiresAt, now, now)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to create campaign approval for campaign %d: %v", approval.CampaignID, err)
+		return err
+	}
+
+	utils.InfoLogger.Infof("Campaign %d is awaiting approval until %s", approval.CampaignID, approval.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+func (s *CampaignApprovalService) Get(id string, userID string) (*models.CampaignApproval, error) {
+	approval, err := scanCampaignApproval(s.db.QueryRow("SELECT "+campaignApprovalColumns+" FROM campaign_approvals WHERE id = ? AND user_id = ?", id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCampaignApprovalNotFound
+	}
+	return approval, err
+}
+
+// List returns the user's approvals, newest first, optionally filtered by status
+func (s *CampaignApprovalService) List(userID string, status models.CampaignApprovalStatus) ([]models.CampaignApproval, error) {
+	query := "SELECT " + campaignApprovalColumns + " FROM campaign_approvals WHERE user_id = ?"
+	args := []interface{}{userID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []models.CampaignApproval
+	for rows.Next() {
+		approval, err := scanCampaignApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, *approval)
+	}
+
+	return approvals, rows.Err()
+}
+
+// Approve schedules the draft campaign. If its send_at has passed while it
+// was waiting, it is moved a few minutes into the future first.
+func (s *CampaignApprovalService) Approve(id string, userID string) (*models.CampaignApproval, error) {
+	approval, err := s.decide(id, userID, models.CampaignApprovalApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleCampaign(approval.CampaignID); err != nil {
+		// Put the approval back so it can be approved again
+		if _, revertErr := s.db.Exec("UPDATE campaign_approvals SET status = ?, decided_at = NULL WHERE id = ?", models.CampaignApprovalPending, id); revertErr != nil {
+			utils.ErrorLogger.Errorf("Failed to revert campaign approval %s: %v", id, revertErr)
+		}
+		return nil, fmt.Errorf("failed to schedule campaign %d: %w", approval.CampaignID, err)
+	}
+
+	utils.InfoLogger.Infof("Approved campaign %d", approval.CampaignID)
+	return approval, nil
+}
+
+// Reject discards the draft campaign
+func (s *CampaignApprovalService) Reject(id string, userID string) (*models.CampaignApproval, error) {
+	approval, err := s.decide(id, userID, models.CampaignApprovalRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.listmonkClient.DeleteCampaign(approval.CampaignID); err != nil {
+		utils.ErrorLogger.Errorf("Failed to delete rejected campaign %d: %v", approval.CampaignID, err)
+	}
+
+	utils.InfoLogger.Infof("Rejected campaign %d", approval.CampaignID)
+	return approval, nil
+}
+
+// Expire discards the draft campaign of an approval nobody decided on. It
+// does nothing when the approval has already been decided.
+func (s *CampaignApprovalService) Expire(id string) error {
+	var campaignID int
+	err := s.db.QueryRow("SELECT campaign_id FROM campaign_approvals WHERE id = ?", id).Scan(&campaignID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec("UPDATE campaign_approvals SET status = ?, decided_at = ? WHERE id = ? AND status = ?",
+		models.CampaignApprovalExpired, time.Now(), id, models.CampaignApprovalPending)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+		return err
+	}
+
+	if err := s.listmonkClient.DeleteCampaign(campaignID); err != nil {
+		utils.ErrorLogger.Errorf("Failed to delete expired campaign %d: %v", campaignID, err)
+	}
+
+	utils.InfoLogger.Infof("Campaign approval %s expired, discarded campaign %d", id, campaignID)
+	return nil
+}
+
+// CancelForCampaign closes the pending approval of a campaign that was
+// discarded for another reason
+func (s *CampaignApprovalService) CancelForCampaign(campaignID int) error {
+	_, err := s.db.Exec("UPDATE campaign_approvals SET status = ?, decided_at = ? WHERE campaign_id = ? AND status = ?",
+		models.CampaignApprovalCancelled, time.Now(), campaignID, models.CampaignApprovalPending)
+	return err
+}
+
+// IsPending reports whether a campaign is waiting for approval
+func (s *CampaignApprovalService) IsPending(campaignID int) (bool, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM campaign_approvals WHERE campaign_id = ? AND status = ?",
+		campaignID, models.CampaignApprovalPending).Scan(&count)
+	return count > 0, err
+}
+
+// decide moves a pending, unexpired approval to the given status. The status
+// check in the UPDATE makes concurrent decisions safe.
+func (s *CampaignApprovalService) decide(id string, userID string, status models.CampaignApprovalStatus) (*models.CampaignApproval, error) {
+	approval, err := s.Get(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec("UPDATE campaign_approvals SET status = ?, decided_at = ? WHERE id = ? AND user_id = ? AND status = ? AND expires_at > ?",
+		status, now, id, userID, models.CampaignApprovalPending, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, ErrCampaignApprovalNotPending
+	}
+
+	approval.Status = status
+	approval.DecidedAt = &now
+	return approval, nil
+}
+
+func (s *CampaignApprovalService) scheduleCampaign(campaignID int) error {
+	campaign, err := s.listmonkClient.GetCampaign(campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.Status != "draft" {
+		return fmt.Errorf("campaign is %s in Listmonk", campaign.Status)
+	}
+
+	earliest := time.Now().Add(minimumSendDelay)
+	if sendAt := parseCampaignSendAt(campaign.SendAt); sendAt.Before(earliest) {
+		if err := s.listmonkClient.RescheduleCampaign(campaignID, earliest); err != nil {
+			return err
+		}
+	}
+
+	return s.listmonkClient.UpdateCampaignStatus(campaignID, "scheduled")
+}
+
+func parseCampaignSendAt(sendAt *string) time.Time {
+	if sendAt == nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, *sendAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func scanCampaignApproval(row rowScanner) (*models.CampaignApproval, error) {
+	var approval models.CampaignApproval
+	var sonID, executionLogID sql.NullString
+	var testEmailsJSON []byte
+	var decidedAt sql.NullTime
+
+	err := row.Scan(&approval.ID, &approval.UserID, &sonID, &executionLogID, &approval.CampaignID, &approval.CampaignName,
+		&testEmailsJSON, &approval.Status, &approval.ExpiresAt, &decidedAt, &approval.CreatedAt, &approval.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	approval.SonID = sonID.String
+	approval.SonExecutionLogID = executionLogID.String
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	if len(testEmailsJSON) > 0 {
+		if err := json.Unmarshal(testEmailsJSON, &approval.TestEmails); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal test emails: %w", err)
+		}
+	}
+	if approval.TestEmails == nil {
+		approval.TestEmails = []string{}
+	}
+
+	return &approval, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeExpireCampaignApproval = "expire_campaign_approval"
+
+// DefaultApprovalExpiry is how long a draft campaign waits for approval when
+// the action does not set approval_expires_in.
+const DefaultApprovalExpiry = "72h"
+
+// requestCampaignApproval sends the test emails for a draft campaign, records
+// the pending approval and schedules its expiry.
+func (e *SonExecutor) requestCampaignApproval(owner actionOwner, executionID string, campaignID int, params map[string]interface{}) error {
+	expiresIn := DefaultApprovalExpiry
+	if v, ok := params["approval_expires_in"].(string); ok && v != "" {
+		expiresIn = v
+	}
+	expiry, err := utils.ParseDuration(expiresIn)
+	if err != nil {
+		return fmt.Errorf("invalid approval_expires_in: %v", err)
+	}
+
+	// A failed test send should not lose the campaign; the approver can
+	// still review the draft in Listmonk.
+	testEmails := getTestEmails(params)
+	if len(testEmails) > 0 {
+		if err := e.listmonkClient.SendCampaignTest(campaignID, testEmails); err != nil {
+			utils.ErrorLogger.Errorf("Failed to send test of campaign %d: %v", campaignID, err)
+			e.executionLogger.LogActionExecution(executionID, "send_campaign_test", models.StateFailed, err.Error())
+		}
+	}
+
+	campaignName, _ := params["name"].(string)
+	approval := &models.CampaignApproval{
+		UserID:            owner.UserID,
+		SonID:             owner.SonID,
+		SonExecutionLogID: executionID,
+		CampaignID:        campaignID,
+		CampaignName:      campaignName,
+		TestEmails:        testEmails,
+		ExpiresAt:         time.Now().Add(expiry),
+	}
+	if err := e.approvals.Create(approval); err != nil {
+		return fmt.Errorf("failed to record campaign approval: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]string{"approval_id": approval.ID})
+	if err != nil {
+		return err
+	}
+	if _, err := e.asyncClient.Enqueue(asynq.NewTask(TypeExpireCampaignApproval, payload), asynq.ProcessAt(approval.ExpiresAt), asynq.Queue("low")); err != nil {
+		// Approval still refuses expired entries, only the cleanup is lost
+		utils.ErrorLogger.Errorf("Failed to schedule expiry of campaign approval %s: %v", approval.ID, err)
+	}
+
+	return nil
+}
+
+func (e *SonExecutor) handleExpireCampaignApproval(ctx context.Context, t *asynq.Task) error {
+	var payload struct {
+		ApprovalID string `json:"approval_id"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	return e.approvals.Expire(payload.ApprovalID)
+}
+
+// getTestEmails reads test_emails, given either as a list or as a comma
+// separated string.
+func getTestEmails(params map[string]interface{}) []string {
+	var emails []string
+	switch v := params["test_emails"].(type) {
+	case string:
+		for _, email := range strings.Split(v, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				emails = append(emails, email)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if email, ok := item.(string); ok && strings.TrimSpace(email) != "" {
+				emails = append(emails, strings.TrimSpace(email))
+			}
+		}
+	}
+	return emails
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/database"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// campaignDeletedStatus marks campaigns that no longer exist in Listmonk. Their
+// last known counters are kept.
+const campaignDeletedStatus = "deleted"
+
+const campaignStatsColumns = `cs.campaign_id, cs.user_id, COALESCE(cs.son_id, ''), COALESCE(s.name, ''), COALESCE(cs.post_id, ''), COALESCE(cs.post_title, ''),
+	cs.campaign_name, cs.status, cs.sent, cs.views, cs.clicks, cs.bounces, cs.synced_at, cs.created_at`
+
+type CampaignStatsService struct {
+	db *sql.DB
+}
+
+func NewCampaignStatsService() *CampaignStatsService {
+	return &CampaignStatsService{db: database.GetDB()}
+}
+
+// Track starts collecting the stats of a campaign created by a Son
+func (s *CampaignStatsService) Track(stats *models.CampaignStats) error {
+	stats.CreatedAt = time.Now()
+	if stats.Status == "" {
+		stats.Status = "draft"
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO campaign_stats (campaign_id, user_id, son_id, post_id, post_title, campaign_name, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE post_title = VALUES(post_title), updated_at = VALUES(updated_at)
+	`, stats.CampaignID, stats.UserID, nullString(stats.SonID), nullString(stats.PostID), nullString(stats.PostTitle), stats.CampaignName,
+		stats.Status, stats.CreatedAt, stats.CreatedAt)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to track stats of campaign %d: %v", stats.CampaignID, err)
+		return err
+	}
+
+	return nil
+}
+
+// ListSyncable returns the campaigns created since the given time that still
+// exist in Listmonk
+func (s *CampaignStatsService) ListSyncable(since time.Time) ([]int, error) {
+	rows, err := s.db.Query("SELECT campaign_id FROM campaign_stats WHERE created_at >= ? AND status <> ?", since, campaignDeletedStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaignIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		campaignIDs = append(campaignIDs, id)
+	}
+
+	return campaignIDs, rows.Err()
+}
+
+// UpdateStats stores the counters fetched from Listmonk
+func (s *CampaignStatsService) UpdateStats(campaign *ListmonkCampaign) error {
+	_, err := s.db.Exec("UPDATE campaign_stats SET status = ?, sent = ?, views = ?, clicks = ?, bounces = ?, synced_at = ? WHERE campaign_id = ?",
+		campaign.Status, campaign.Sent, campaign.Views, campaign.Clicks, campaign.Bounces, time.Now(), campaign.ID)
+	return err
+}
+
+// MarkDeleted stops syncing a campaign that was deleted in Listmonk
+func (s *CampaignStatsService) MarkDeleted(campaignID int) error {
+	_, err := s.db.Exec("UPDATE campaign_stats SET status = ?, synced_at = ? WHERE campaign_id = ?", campaignDeletedStatus, time.Now(), campaignID)
+	return err
+}
+
+// ListBySon returns the campaigns of a Son, newest first
+func (s *CampaignStatsService) ListBySon(userID string, sonID string) ([]models.CampaignStats, error) {
+	return s.list("cs.user_id = ? AND cs.son_id = ?", userID, sonID)
+}
+
+// ListByPost returns the campaigns created for a post, newest first
+func (s *CampaignStatsService) ListByPost(userID string, postID string) ([]models.CampaignStats, error) {
+	return s.list("cs.user_id = ? AND cs.post_id = ?", userID, postID)
+}
+
+// SummarizeBySon adds up the campaigns of every Son of the user
+func (s *CampaignStatsService) SummarizeBySon(userID string) ([]models.CampaignStatsSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT s.id, s.name, COUNT(*), SUM(cs.sent), SUM(cs.views), SUM(cs.clicks), SUM(cs.bounces)
+		FROM campaign_stats cs
+		JOIN sons s ON cs.son_id = s.id
+		WHERE cs.user_id = ?
+		GROUP BY s.id, s.name
+		ORDER BY SUM(cs.views) DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.CampaignStatsSummary
+	for rows.Next() {
+		var summary models.CampaignStatsSummary
+		if err := rows.Scan(&summary.SonID, &summary.Name, &summary.Campaigns, &summary.Sent, &summary.Views, &summary.Clicks, &summary.Bounces); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// SummarizeByPost adds up the campaigns created for every post of the user
+func (s *CampaignStatsService) SummarizeByPost(userID string) ([]models.CampaignStatsSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT cs.post_id, COALESCE(MAX(cs.post_title), ''), COUNT(*), SUM(cs.sent), SUM(cs.views), SUM(cs.clicks), SUM(cs.bounces)
+		FROM campaign_stats cs
+		WHERE cs.user_id = ? AND cs.post_id IS NOT NULL
+		GROUP BY cs.post_id
+		ORDER BY MAX(cs.created_at) DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.CampaignStatsSummary
+	for rows.Next() {
+		var summary models.CampaignStatsSummary
+		if err := rows.Scan(&summary.PostID, &summary.Name, &summary.Campaigns, &summary.Sent, &summary.Views, &summary.Clicks, &summary.Bounces); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (s *CampaignStatsService) list(where string, args ...interface{}) ([]models.CampaignStats, error) {
+	rows, err := s.db.Query("SELECT "+campaignStatsColumns+" FROM campaign_stats cs LEFT JOIN sons s ON cs.son_id = s.id WHERE "+where+" ORDER BY cs.created_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []models.CampaignStats
+	for rows.Next() {
+		var stats models.CampaignStats
+		var syncedAt sql.NullTime
+		err := rows.Scan(&stats.CampaignID, &stats.UserID, &stats.SonID, &stats.SonName, &stats.PostID, &stats.PostTitle,
+			&stats.CampaignName, &stats.Status, &stats.Sent, &stats.Views, &stats.Clicks, &stats.Bounces, &syncedAt, &stats.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if syncedAt.Valid {
+			stats.SyncedAt = &syncedAt.Time
+		}
+		campaigns = append(campaigns, stats)
+	}
+
+	return campaigns, rows.Err()
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeSyncCampaignStats = "sync_campaign_stats"
+
+// campaignStatsWindow is how long after its creation a campaign keeps having
+// its stats refreshed. Views and clicks rarely move after that.
+const campaignStatsWindow = 30 * 24 * time.Hour
+
+// ScheduleCampaignStatsSync refreshes the stats of recent campaigns at the
+// given interval. The task is unique per interval so several instances do
+// not fetch the same stats twice.
+func (e *SonExecutor) ScheduleCampaignStatsSync(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("campaign stats interval must be positive, got %s", interval)
+	}
+	_, err := e.scheduler.Register(fmt.Sprintf("@every %s", interval), asynq.NewTask(TypeSyncCampaignStats, nil),
+		asynq.Queue("low"), asynq.MaxRetry(0), asynq.Unique(interval))
+	return err
+}
+
+func (e *SonExecutor) handleSyncCampaignStats(ctx context.Context, t *asynq.Task) error {
+	campaignIDs, err := e.campaignStats.ListSyncable(time.Now().Add(-campaignStatsWindow))
+	if err != nil {
+		return fmt.Errorf("failed to list campaigns: %w", err)
+	}
+
+	synced := 0
+	for _, campaignID := range campaignIDs {
+		campaign, err := e.listmonkClient.GetCampaign(campaignID)
+		if err == ErrCampaignNotFound {
+			if err := e.campaignStats.MarkDeleted(campaignID); err != nil {
+				utils.ErrorLogger.Errorf("Failed to mark campaign %d as deleted: %v", campaignID, err)
+			}
+			continue
+		}
+		if err != nil {
+			// Keep going, the next run picks it up again
+			utils.ErrorLogger.Errorf("Failed to fetch stats of campaign %d: %v", campaignID, err)
+			continue
+		}
+
+		if err := e.campaignStats.UpdateStats(campaign); err != nil {
+			utils.ErrorLogger.Errorf("Failed to store stats of campaign %d: %v", campaignID, err)
+			continue
+		}
+		synced++
+	}
+
+	utils.InfoLogger.Infof("Synced stats of %d/%d campaigns", synced, len(campaignIDs))
+	return nil
+}
+
+// trackCampaignStats registers a new campaign for the stats sync. A failure
+// only costs the stats, so it is logged rather than failing the action.
+func (e *SonExecutor) trackCampaignStats(owner actionOwner, campaignID int, params map[string]interface{}, data map[string]interface{}) {
+	name, _ := params["name"].(string)
+	stats := &models.CampaignStats{
+		CampaignID:   campaignID,
+		UserID:       owner.UserID,
+		SonID:        owner.SonID,
+		PostID:       getPostID(data),
+		PostTitle:    getPostTitle(data),
+		CampaignName: name,
+	}
+	if err := e.campaignStats.Track(stats); err != nil {
+		utils.ErrorLogger.Errorf("Failed to track stats of campaign %d: %v", campaignID, err)
+	}
+}
+
+func getPostTitle(data map[string]interface{}) string {
+	post, _ := data["post"].(map[string]interface{})
+	current, _ := post["current"].(map[string]interface{})
+	title, _ := current["title"].(string)
+	return title
+}
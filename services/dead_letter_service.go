@@ -0,0 +1,224 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// deadLetterPageSize is how many archived tasks are read from Redis at once
+const deadLetterPageSize = 100
+
+// DeadLetterService exposes the action tasks asynq archived once they ran
+// out of retries, so they can be retried or discarded by hand.
+type DeadLetterService struct {
+	inspector       *asynq.Inspector
+	executionLogger *SonExecutionLogger
+	idempotency     *IdempotencyStore
+}
+
+func NewDeadLetterService(redisAddr string, executionLogger *SonExecutionLogger, idempotency *IdempotencyStore) *DeadLetterService {
+	return &DeadLetterService{
+		inspector:       asynq.NewInspector(asynq.RedisClientOpt{Addr: redisAddr}),
+		executionLogger: executionLogger,
+		idempotency:     idempotency,
+	}
+}
+
+// actionTaskPayload is the part of an action task payload dead letters read
+type actionTaskPayload struct {
+	Action      models.Action `json:"action"`
+	ActionIndex *int          `json:"action_index"`
+	ExecutionID string        `json:"execution_id"`
+	SonID       string        `json:"son_id"`
+	SonName     string        `json:"son_name"`
+	UserID      string        `json:"user_id"`
+}
+
+// List returns the user's dead-lettered actions, most recently failed first
+// as asynq orders them. Archived tasks are shared by every user, so they are
+// filtered before paginating.
+func (s *DeadLetterService) List(userID string, limit, offset int) ([]models.DeadLetter, int, error) {
+	deadLetters, err := s.listAll(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(deadLetters)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return deadLetters[offset:end], total, nil
+}
+
+func (s *DeadLetterService) listAll(userID string) ([]models.DeadLetter, error) {
+	var deadLetters []models.DeadLetter
+	for page := 1; ; page++ {
+		tasks, err := s.inspector.ListArchivedTasks(actionQueue, asynq.PageSize(deadLetterPageSize), asynq.Page(page))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return deadLetters, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list archived tasks: %w", err)
+		}
+
+		for _, task := range tasks {
+			if deadLetter, owner, ok := newDeadLetter(task); ok && owner == userID {
+				deadLetters = append(deadLetters, deadLetter)
+			}
+		}
+		if len(tasks) < deadLetterPageSize {
+			return deadLetters, nil
+		}
+	}
+}
+
+// Get returns one of the user's dead-lettered actions
+func (s *DeadLetterService) Get(userID string, id string) (models.DeadLetter, error) {
+	task, err := s.inspector.GetTaskInfo(actionQueue, id)
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return models.DeadLetter{}, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return models.DeadLetter{}, err
+	}
+
+	deadLetter, owner, ok := newDeadLetter(task)
+	if !ok || owner != userID || task.State != asynq.TaskStateArchived {
+		return models.DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return deadLetter, nil
+}
+
+// Retry queues a dead-lettered action to run again right away. It gets a
+// single attempt, failing again puts it back among the dead letters.
+func (s *DeadLetterService) Retry(userID string, id string) error {
+	deadLetter, err := s.Get(userID, id)
+	if err != nil {
+		return err
+	}
+
+	// The step is queued before the task can run, so the worker finds it in
+	// a state it can start from
+	requeued := false
+	if deadLetter.ActionIndex != nil {
+		if err := s.executionLogger.TransitionStep(deadLetter.ExecutionID, *deadLetter.ActionIndex, models.StateQueued, ""); err != nil {
+			utils.ErrorLogger.Errorf("Failed to requeue step %d of execution %s: %v", *deadLetter.ActionIndex, deadLetter.ExecutionID, err)
+		} else {
+			requeued = true
+		}
+	}
+
+	// Retrying by hand decides to repeat side effects an interrupted attempt
+	// may have completed, such as sending an email
+	if deadLetter.ActionIndex != nil {
+		if err := s.idempotency.ClearStarted(deadLetter.ExecutionID, *deadLetter.ActionIndex); err != nil {
+			utils.ErrorLogger.Errorf("Failed to clear started side effects of step %d of execution %s: %v", *deadLetter.ActionIndex, deadLetter.ExecutionID, err)
+		}
+	}
+
+	if err := s.inspector.RunTask(deadLetter.Queue, deadLetter.ID); err != nil {
+		if requeued {
+			if stepErr := s.executionLogger.TransitionStep(deadLetter.ExecutionID, *deadLetter.ActionIndex, models.StateFailed, deadLetter.LastError); stepErr != nil {
+				utils.ErrorLogger.Errorf("Failed to restore step %d of execution %s: %v", *deadLetter.ActionIndex, deadLetter.ExecutionID, stepErr)
+			}
+		}
+		return fmt.Errorf("failed to run task %s: %w", id, err)
+	}
+
+	utils.InfoLogger.Infof("Retrying dead-lettered %s task %s", deadLetter.ActionType, id)
+	return nil
+}
+
+// Discard deletes a dead-lettered action for good
+func (s *DeadLetterService) Discard(userID string, id string) error {
+	deadLetter, err := s.Get(userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.inspector.DeleteTask(deadLetter.Queue, deadLetter.ID); err != nil {
+		return fmt.Errorf("failed to delete task %s: %w", id, err)
+	}
+
+	utils.InfoLogger.Infof("Discarded dead-lettered %s task %s", deadLetter.ActionType, id)
+	return nil
+}
+
+// RetryMany retries the given dead letters, or all of the user's when ids is
+// empty. Each goes through Retry, so its step is queued before its task
+// runs. It returns how many were retried.
+func (s *DeadLetterService) RetryMany(userID string, ids []string) (int, error) {
+	return s.applyMany(userID, ids, s.Retry)
+}
+
+// DiscardMany discards the given dead letters, or all of the user's when ids
+// is empty. It returns how many were discarded.
+func (s *DeadLetterService) DiscardMany(userID string, ids []string) (int, error) {
+	return s.applyMany(userID, ids, s.Discard)
+}
+
+func (s *DeadLetterService) applyMany(userID string, ids []string, apply func(userID string, id string) error) (int, error) {
+	if len(ids) == 0 {
+		deadLetters, err := s.listAll(userID)
+		if err != nil {
+			return 0, err
+		}
+		for _, deadLetter := range deadLetters {
+			ids = append(ids, deadLetter.ID)
+		}
+	}
+
+	done := 0
+	var errs []error
+	for _, id := range ids {
+		if err := apply(userID, id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+func (s *DeadLetterService) Close() error {
+	return s.inspector.Close()
+}
+
+// newDeadLetter decodes an archived action task, returning the ID of the
+// user it belongs to. Tasks other than actions are left out.
+func newDeadLetter(task *asynq.TaskInfo) (models.DeadLetter, string, bool) {
+	if !isActionTask(task.Type) {
+		return models.DeadLetter{}, "", false
+	}
+
+	var payload actionTaskPayload
+	if err := json.Unmarshal(task.Payload, &payload); err != nil {
+		return models.DeadLetter{}, "", false
+	}
+
+	return models.DeadLetter{
+		ID:           task.ID,
+		Queue:        task.Queue,
+		ActionType:   string(payload.Action.Type),
+		ActionIndex:  payload.ActionIndex,
+		SonID:        payload.SonID,
+		SonName:      payload.SonName,
+		ExecutionID:  payload.ExecutionID,
+		Payload:      json.RawMessage(task.Payload),
+		LastError:    task.LastErr,
+		LastFailedAt: task.LastFailedAt,
+		Retried:      task.Retried,
+		MaxRetry:     task.MaxRetry,
+	}, payload.UserID, true
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// recordStep stores an action as a step of the execution before its task is
+// queued, so the task always finds its step. A failure only costs the
+// progress view, so it is logged rather than failing the run.
+func (e *SonExecutor) recordStep(executionID string, actionIndex int, actionType models.ActionType, taskID string, state models.ExecutionState, scheduledFor time.Time, errorMessage string) {
+	step := &models.ExecutionStep{
+		ExecutionLogID: executionID,
+		ActionIndex:    actionIndex,
+		ActionType:     string(actionType),
+		TaskID:         taskID,
+		Queue:          actionQueue,
+		Status:         state,
+		ScheduledFor:   scheduledFor,
+		ErrorMessage:   errorMessage,
+	}
+	if err := e.executionLogger.RecordStep(step); err != nil {
+		utils.ErrorLogger.Errorf("Failed to record step %d of execution %s: %v", actionIndex, executionID, err)
+	}
+}
+
+// failStep records an action that could not be queued
+func (e *SonExecutor) failStep(executionID string, actionIndex int, actionType models.ActionType, scheduledFor time.Time, errorMessage string) {
+	e.recordStep(executionID, actionIndex, actionType, "", models.StateFailed, scheduledFor, errorMessage)
+	e.executionLogger.LogActionExecution(executionID, string(actionType), models.StateFailed, errorMessage)
+}
+
+// transitionStep moves a step to another state. Steps of tasks queued before
+// actions were tracked do not exist, and a step cancelled while its task ran
+// refuses to move on, so neither is an error worth reporting.
+func (e *SonExecutor) transitionStep(executionID string, actionIndex int, to models.ExecutionState, errorMessage string) {
+	err := e.executionLogger.TransitionStep(executionID, actionIndex, to, errorMessage)
+	switch {
+	case err == nil, errors.Is(err, ErrExecutionStepNotFound):
+	case errors.Is(err, ErrInvalidTransition):
+		utils.InfoLogger.Infof("Left step %d of execution %s unchanged: %v", actionIndex, executionID, err)
+	default:
+		utils.ErrorLogger.Errorf("Failed to update step %d of execution %s: %v", actionIndex, executionID, err)
+	}
+}
+
+// trackSteps is a middleware moving the step of an action task through its
+// states: running while the handler runs, then succeeded, failed once it
+// runs out of retries, or queued again for the next attempt.
+func (e *SonExecutor) trackSteps(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+		if !isActionTask(t.Type()) {
+			return next.ProcessTask(ctx, t)
+		}
+
+		step, ok := parseActionStep(t)
+		if !ok {
+			return next.ProcessTask(ctx, t)
+		}
+
+		e.transitionStep(step.ExecutionID, *step.ActionIndex, models.StateRunning, "")
+		defer func() {
+			// asynq turns a panic into an error, which would leave the step running
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+
+			switch {
+			case err == nil:
+				e.transitionStep(step.ExecutionID, *step.ActionIndex, models.StateSucceeded, "")
+			case isFinalAttempt(ctx, err):
+				e.transitionStep(step.ExecutionID, *step.ActionIndex, models.StateFailed, err.Error())
+			default:
+				e.transitionStep(step.ExecutionID, *step.ActionIndex, models.StateQueued, err.Error())
+			}
+		}()
+
+		return next.ProcessTask(ctx, t)
+	})
+}
+
+// actionStep identifies an action of an execution
+type actionStep struct {
+	ExecutionID string `json:"execution_id"`
+	ActionIndex *int   `json:"action_index"`
+}
+
+// parseActionStep reads the step of an action task. Tasks queued before
+// actions were tracked as steps have none.
+func parseActionStep(t *asynq.Task) (actionStep, bool) {
+	var step actionStep
+	if json.Unmarshal(t.Payload(), &step) != nil || step.ExecutionID == "" || step.ActionIndex == nil {
+		return actionStep{}, false
+	}
+	return step, true
+}
+
+// Idempotency keys of the side effects of actions
+const (
+	idempotencyActionCompleted   = "action_completed"
+	idempotencyRequestSent       = "request_sent"
+	idempotencyCampaignCreated   = "campaign_created"
+	idempotencyApprovalRequested = "approval_requested"
+	idempotencyCampaignScheduled = "campaign_scheduled"
+)
+
+// once performs a side effect of an action step a single time per
+// execution. When a retry reaches a side effect an earlier attempt already
+// completed, the result that attempt recorded is returned instead of running
+// it again. Steps without an action index always run.
+func (e *SonExecutor) once(t *asynq.Task, key string, run func() (string, error)) (string, error) {
+	step, ok := parseActionStep(t)
+	if !ok {
+		return run()
+	}
+
+	result, err := e.idempotency.Get(step.ExecutionID, *step.ActionIndex, key)
+	if err == nil {
+		utils.InfoLogger.Infof("Step %d of execution %s already completed %s, resuming after it", *step.ActionIndex, step.ExecutionID, key)
+		return result, nil
+	}
+	if err != ErrIdempotencyKeyNotFound {
+		return "", fmt.Errorf("failed to check %s of step %d: %w", key, *step.ActionIndex, err)
+	}
+
+	result, err = run()
+	if err != nil {
+		return "", err
+	}
+
+	// The side effect happened, so failing the task now would repeat it
+	if err := e.idempotency.Save(step.ExecutionID, *step.ActionIndex, key, result); err != nil {
+		utils.ErrorLogger.Errorf("Failed to record %s of step %d of execution %s: %v", key, *step.ActionIndex, step.ExecutionID, err)
+	}
+	return result, nil
+}
+
+// startedKeySuffix marks the key of a side effect atMostOnce has started
+const startedKeySuffix = "_started"
+
+// atMostOnce performs a side effect that must not be repeated, such as
+// sending an email, like once, but records that it started before running
+// it. A retry of an attempt that timed out or was interrupted after it
+// started cannot tell whether it went through, so the step fails without
+// retrying instead of repeating it, and is left to be retried by hand from
+// its dead letter. Failures that show it did not go through are retried.
+func (e *SonExecutor) atMostOnce(t *asynq.Task, key string, run func() (string, error)) (string, error) {
+	step, ok := parseActionStep(t)
+	if !ok {
+		return run()
+	}
+	startedKey := key + startedKeySuffix
+
+	result, err := e.idempotency.Get(step.ExecutionID, *step.ActionIndex, key)
+	if err == nil {
+		utils.InfoLogger.Infof("Step %d of execution %s already completed %s, resuming after it", *step.ActionIndex, step.ExecutionID, key)
+		return result, nil
+	}
+	if err != ErrIdempotencyKeyNotFound {
+		return "", fmt.Errorf("failed to check %s of step %d: %w", key, *step.ActionIndex, err)
+	}
+
+	_, err = e.idempotency.Get(step.ExecutionID, *step.ActionIndex, startedKey)
+	if err == nil {
+		return "", fmt.Errorf("an earlier attempt of step %d was interrupted and may have gone through, retry it by hand to repeat it: %w", *step.ActionIndex, asynq.SkipRetry)
+	}
+	if err != ErrIdempotencyKeyNotFound {
+		return "", fmt.Errorf("failed to check %s of step %d: %w", startedKey, *step.ActionIndex, err)
+	}
+	if err := e.idempotency.Save(step.ExecutionID, *step.ActionIndex, startedKey, ""); err != nil {
+		return "", fmt.Errorf("failed to record %s of step %d: %w", startedKey, *step.ActionIndex, err)
+	}
+
+	result, err = run()
+	if err != nil {
+		if isTimeout(err) {
+			return "", fmt.Errorf("%w: it may have gone through, so it is not retried: %w", err, asynq.SkipRetry)
+		}
+		if !errors.Is(err, asynq.SkipRetry) {
+			if err := e.idempotency.Delete(step.ExecutionID, *step.ActionIndex, startedKey); err != nil {
+				utils.ErrorLogger.Errorf("Failed to forget %s of step %d of execution %s: %v", startedKey, *step.ActionIndex, step.ExecutionID, err)
+			}
+		}
+		return "", err
+	}
+
+	// The side effect happened, so failing the task now would repeat it
+	if err := e.idempotency.Save(step.ExecutionID, *step.ActionIndex, key, result); err != nil {
+		utils.ErrorLogger.Errorf("Failed to record %s of step %d of execution %s: %v", key, *step.ActionIndex, step.ExecutionID, err)
+	}
+	return result, nil
+}
+
+// isTimeout reports whether err is a request that timed out, which may have
+// reached the other end before it did
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// completed reports whether an earlier attempt of the step completed key
+func (e *SonExecutor) completed(t *asynq.Task, key string) (bool, error) {
+	step, ok := parseActionStep(t)
+	if !ok {
+		return false, nil
+	}
+	_, err := e.idempotency.Get(step.ExecutionID, *step.ActionIndex, key)
+	if err == ErrIdempotencyKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isActionTask(taskType string) bool {
+	for _, t := range actionTaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalAttempt reports whether a failed task will not be retried
+func isFinalAttempt(ctx context.Context, err error) bool {
+	if errors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return false
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return ok && retried >= maxRetry
+}
+
+// retryDelay applies the retry policy of action tasks, and the default
+// backoff to every other task.
+func retryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if isActionTask(t.Type()) {
+		var payload struct {
+			Action models.Action `json:"action"`
+		}
+		if json.Unmarshal(t.Payload(), &payload) == nil && payload.Action.Retry != nil {
+			if delay, ok := payload.Action.Retry.RetryDelay(n); ok {
+				return delay
+			}
+		}
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, t)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hibiken/asynq"
+)
+
+// memoryIdempotencyStore keeps idempotency keys in memory
+type memoryIdempotencyStore struct {
+	keys map[string]string
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]string)}
+}
+
+func memoryKey(executionID string, actionIndex int, key string) string {
+	return fmt.Sprintf("%s/%d/%s", executionID, actionIndex, key)
+}
+
+func (s *memoryIdempotencyStore) Get(executionID string, actionIndex int, key string) (string, error) {
+	result, ok := s.keys[memoryKey(executionID, actionIndex, key)]
+	if !ok {
+		return "", ErrIdempotencyKeyNotFound
+	}
+	return result, nil
+}
+
+func (s *memoryIdempotencyStore) Save(executionID string, actionIndex int, key string, result string) error {
+	if _, ok := s.keys[memoryKey(executionID, actionIndex, key)]; !ok {
+		s.keys[memoryKey(executionID, actionIndex, key)] = result
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Delete(executionID string, actionIndex int, key string) error {
+	delete(s.keys, memoryKey(executionID, actionIndex, key))
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Clear(executionID string, actionIndex int) error {
+	s.keys = make(map[string]string)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) HasPendingStep(key string, result string) (bool, error) {
+	return false, nil
+}
+
+// timeoutError is a request that timed out, like the ones net/http returns
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var errUnavailable = errors.New("service unavailable")
+
+// attempt is one run of a task: the error its side effect fails with, if it
+// runs at all
+type attempt struct {
+	err     error
+	wantRun bool
+	wantErr error
+}
+
+func TestIdempotentSideEffects(t *testing.T) {
+	stepTask := asynq.NewTask(TypeSendTransactionalEmail, []byte(`{"execution_id": "exec-1", "action_index": 0}`))
+	legacyTask := asynq.NewTask(TypeSendTransactionalEmail, []byte(`{"execution_id": "exec-1"}`))
+
+	tests := []struct {
+		name       string
+		atMostOnce bool
+		task       *asynq.Task
+		attempts   []attempt
+	}{
+		{
+			name: "once does not repeat a completed side effect",
+			task: stepTask,
+			attempts: []attempt{
+				{wantRun: true},
+				{wantRun: false},
+			},
+		},
+		{
+			name: "once repeats a failed side effect",
+			task: stepTask,
+			attempts: []attempt{
+				{err: timeoutError{}, wantRun: true, wantErr: timeoutError{}},
+				{wantRun: true},
+				{wantRun: false},
+			},
+		},
+		{
+			name: "once always runs tasks without a step",
+			task: legacyTask,
+			attempts: []attempt{
+				{wantRun: true},
+				{wantRun: true},
+			},
+		},
+		{
+			name:       "at most once does not repeat a completed side effect",
+			atMostOnce: true,
+			task:       stepTask,
+			attempts: []attempt{
+				{wantRun: true},
+				{wantRun: false},
+			},
+		},
+		{
+			name:       "at most once retries a side effect that did not go through",
+			atMostOnce: true,
+			task:       stepTask,
+			attempts: []attempt{
+				{err: errUnavailable, wantRun: true, wantErr: errUnavailable},
+				{wantRun: true},
+				{wantRun: false},
+			},
+		},
+		{
+			name:       "at most once does not retry a timeout",
+			atMostOnce: true,
+			task:       stepTask,
+			attempts: []attempt{
+				{err: timeoutError{}, wantRun: true, wantErr: asynq.SkipRetry},
+				{wantRun: false, wantErr: asynq.SkipRetry},
+			},
+		},
+		{
+			name:       "at most once does not retry a deadline",
+			atMostOnce: true,
+			task:       stepTask,
+			attempts: []attempt{
+				{err: fmt.Errorf("request failed: %w", context.DeadlineExceeded), wantRun: true, wantErr: asynq.SkipRetry},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &SonExecutor{idempotency: newMemoryIdempotencyStore()}
+			perform := e.once
+			if tt.atMostOnce {
+				perform = e.atMostOnce
+			}
+
+			for i, a := range tt.attempts {
+				ran := false
+				result, err := perform(tt.task, idempotencyActionCompleted, func() (string, error) {
+					ran = true
+					return "sent", a.err
+				})
+				if ran != a.wantRun {
+					t.Errorf("attempt %d: ran = %v, want %v", i+1, ran, a.wantRun)
+				}
+				if a.wantErr != nil {
+					if !errors.Is(err, a.wantErr) {
+						t.Errorf("attempt %d: error = %v, want %v", i+1, err, a.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("attempt %d: unexpected error: %v", i+1, err)
+				}
+				if result != "sent" {
+					t.Errorf("attempt %d: result = %q, want %q", i+1, result, "sent")
+				}
+			}
+		})
+	}
+}
+
+func TestAtMostOnceInterruptedAttempt(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	e := &SonExecutor{idempotency: store}
+	task := asynq.NewTask(TypeHTTPRequest, []byte(`{"execution_id": "exec-1", "action_index": 2}`))
+
+	// The worker stopped after the request started, before it was recorded
+	store.Save("exec-1", 2, idempotencyRequestSent+startedKeySuffix, "")
+
+	_, err := e.atMostOnce(task, idempotencyRequestSent, func() (string, error) {
+		t.Error("an interrupted request was sent again")
+		return "200", nil
+	})
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("error = %v, want %v", err, asynq.SkipRetry)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// cancelMemberSteps cancels the steps still queued for a member by the Sons
+// that exit on one of the conditions. Each cancellation is recorded in the
+// action logs of its execution together with the reason.
+func (e *SonExecutor) cancelMemberSteps(userID string, memberEmail string, exits []models.ExitCondition, sons []models.Son) error {
+	if memberEmail == "" || len(exits) == 0 {
+		return nil
+	}
+
+	steps, err := e.executionLogger.ListQueuedMemberSteps(userID, memberEmail)
+	if err != nil {
+		return fmt.Errorf("failed to list queued steps: %w", err)
+	}
+
+	sonsByID := make(map[string]models.Son, len(sons))
+	for _, son := range sons {
+		sonsByID[son.ID] = son
+	}
+
+	cancelled := 0
+	for _, step := range steps {
+		son, ok := sonsByID[step.SonID]
+		if !ok {
+			continue
+		}
+		for _, exit := range exits {
+			if !son.ExitsOn(exit) {
+				continue
+			}
+			if err := e.cancelStep(step, exit.Reason()); err != nil {
+				utils.ErrorLogger.Errorf("Failed to cancel step %d of execution %s: %v", step.ActionIndex, step.ExecutionLogID, err)
+			} else {
+				cancelled++
+			}
+			break
+		}
+	}
+
+	if cancelled > 0 {
+		utils.InfoLogger.Infof("Cancelled %d queued steps of member %s", cancelled, memberEmail)
+	}
+	return nil
+}
+
+// cancelStep removes the task of a queued step and records the cancellation.
+// A task no longer in the queue has already run, and its outcome is recorded
+// by its handler instead.
+func (e *SonExecutor) cancelStep(step models.ExecutionStep, reason string) error {
+	if step.TaskID != "" {
+		err := e.inspector.DeleteTask(step.Queue, step.TaskID)
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return e.recordCancellation(step.ExecutionLogID, step.ActionIndex, step.ActionType, reason)
+}
+
+func (e *SonExecutor) recordCancellation(executionID string, actionIndex int, actionType string, reason string) error {
+	err := e.executionLogger.TransitionStep(executionID, actionIndex, models.StateCancelled, reason)
+	if err != nil && !errors.Is(err, ErrExecutionStepNotFound) {
+		return err
+	}
+	return e.executionLogger.LogActionExecution(executionID, actionType, models.StateCancelled, reason)
+}
+
+// checkExitConditions is a middleware dropping the delayed steps of Sons that
+// exit on member_unsubscribed once the member has unsubscribed in Listmonk,
+// which sends no webhook for it. The remaining steps of the member are
+// cancelled along with it.
+func (e *SonExecutor) checkExitConditions(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if !isActionTask(t.Type()) {
+			return next.ProcessTask(ctx, t)
+		}
+
+		var payload struct {
+			Action      models.Action          `json:"action"`
+			ActionIndex *int                   `json:"action_index"`
+			Data        map[string]interface{} `json:"data"`
+			ExecutionID string                 `json:"execution_id"`
+			SonID       string                 `json:"son_id"`
+			UserID      string                 `json:"user_id"`
+		}
+		if json.Unmarshal(t.Payload(), &payload) != nil || payload.ActionIndex == nil {
+			return next.ProcessTask(ctx, t)
+		}
+
+		son, err := e.sonStorage.Get(payload.SonID)
+		if err != nil || !son.ExitsOn(models.ExitMemberUnsubscribed) {
+			return next.ProcessTask(ctx, t)
+		}
+		// A step due right away is part of the reaction to the webhook itself
+		sonDelay, _ := son.GetParsedDelay()
+		if !payload.Action.Deferred(sonDelay) {
+			return next.ProcessTask(ctx, t)
+		}
+
+		email, err := getSubscriberEmail(payload.Data)
+		if err != nil {
+			return next.ProcessTask(ctx, t)
+		}
+		unsubscribed, err := e.isUnsubscribed(email)
+		if err != nil {
+			return fmt.Errorf("failed to check subscription of %s: %w", email, err)
+		}
+		if !unsubscribed {
+			return next.ProcessTask(ctx, t)
+		}
+
+		exit := models.ExitMemberUnsubscribed
+		utils.InfoLogger.Infof("Skipping step %d of execution %s: %s", *payload.ActionIndex, payload.ExecutionID, exit.Reason())
+		if err := e.recordCancellation(payload.ExecutionID, *payload.ActionIndex, string(payload.Action.Type), exit.Reason()); err != nil {
+			utils.ErrorLogger.Errorf("Failed to record cancellation of step %d of execution %s: %v", *payload.ActionIndex, payload.ExecutionID, err)
+		}
+
+		sons, err := e.sonStorage.List(payload.UserID)
+		if err != nil {
+			utils.ErrorLogger.Errorf("Failed to list Sons: %v", err)
+			return nil
+		}
+		if err := e.cancelMemberSteps(payload.UserID, email, []models.ExitCondition{exit}, sons); err != nil {
+			utils.ErrorLogger.Errorf("Failed to cancel steps of member %s: %v", email, err)
+		}
+		return nil
+	})
+}
+
+// isUnsubscribed reports whether the subscriber is blocklisted or has
+// unsubscribed from every list in Listmonk
+func (e *SonExecutor) isUnsubscribed(email string) (bool, error) {
+	subscriber, err := e.listmonkClient.GetSubscriberByEmail(email)
+	if err == ErrSubscriberNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if subscriber.Status == "blocklisted" {
+		return true, nil
+	}
+	if len(subscriber.Lists) == 0 {
+		return false, nil
+	}
+	for _, list := range subscriber.Lists {
+		if list.SubscriptionStatus != "unsubscribed" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeHTTPRequest = "http_request"
+
+// maxLoggedResponseBody caps how much of a response is kept in the action log
+const maxLoggedResponseBody = 16 * 1024
+
+const defaultSignatureHeader = "X-Signature"
+
+// maxRedirects caps how many redirects an outbound request follows
+const maxRedirects = 5
+
+// outboundHTTPClient sends the requests of http_request actions. Tenants
+// choose the URL, so it only connects to public addresses, checked once the
+// host name is resolved, and never through a proxy. Redirects are dialled
+// through the same check.
+var outboundHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   utils.PublicAddressControl,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+	},
+	CheckRedirect: checkOutboundRedirect,
+}
+
+// checkOutboundRedirect refuses redirects to other schemes and to internal
+// IP literals, and stops after maxRedirects
+func checkOutboundRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %s", req.URL.Scheme)
+	}
+	if ip := net.ParseIP(req.URL.Hostname()); ip != nil && !utils.IsPublicIP(ip) {
+		return fmt.Errorf("redirect to %s: %w", req.URL.Host, utils.ErrForbiddenAddress)
+	}
+	return nil
+}
+
+func (e *SonExecutor) handleHTTPRequest(ctx context.Context, t *asynq.Task) error {
+	executionID, params, data, err := parseActionPayload(t)
+	if err != nil {
+		return err
+	}
+
+	// A request an earlier attempt sent, or may have sent, is not sent again
+	var responseStatus int
+	var responseBody string
+	result, err := e.atMostOnce(t, idempotencyRequestSent, func() (string, error) {
+		var err error
+		responseStatus, responseBody, err = sendHTTPRequest(ctx, params, data)
+		return strconv.Itoa(responseStatus), err
+	})
+	if responseStatus == 0 {
+		responseStatus, _ = strconv.Atoi(result)
+	}
+	if err != nil {
+		e.executionLogger.LogActionResponse(executionID, string(models.ActionHTTPRequest), models.StateFailed, err.Error(), responseStatus, responseBody)
+		return err
+	}
+
+	e.executionLogger.LogActionResponse(executionID, string(models.ActionHTTPRequest), models.StateSucceeded, "", responseStatus, responseBody)
+	return nil
+}
+
+// sendHTTPRequest performs the request described by the action parameters:
+//
+//	url               target URL (required)
+//	method            GET, POST, PUT, PATCH or DELETE (default POST)
+//	headers           [{key, value}] request headers
+//	body              text/template rendered over the webhook data
+//	secret            signs the body like Ghost does when set
+//	signature_header  header carrying the signature (default X-Signature)
+//
+// Failures are retried by asynq, except for client errors that will not
+// succeed on a retry.
+func sendHTTPRequest(ctx context.Context, params map[string]interface{}, data map[string]interface{}) (int, string, error) {
+	url, ok := params["url"].(string)
+	if !ok || url == "" {
+		return 0, "", fmt.Errorf("invalid or missing url: %w", asynq.SkipRetry)
+	}
+
+	method := http.MethodPost
+	if m, ok := params["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	var body []byte
+	if tmpl, ok := params["body"].(string); ok && tmpl != "" {
+		rendered, err := utils.RenderTextTemplate("body", tmpl, data)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to render body: %v: %w", err, asynq.SkipRetry)
+		}
+		body = []byte(rendered)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to build request: %v: %w", err, asynq.SkipRetry)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	headers, err := getHeaders(params)
+	if err != nil {
+		return 0, "", fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	for _, header := range headers {
+		if header["key"] != "" {
+			req.Header.Set(header["key"], header["value"])
+		}
+	}
+
+	if secret, ok := params["secret"].(string); ok && secret != "" {
+		signatureHeader := defaultSignatureHeader
+		if h, ok := params["signature_header"].(string); ok && h != "" {
+			signatureHeader = h
+		}
+		req.Header.Set(signatureHeader, SignatureHeader(body, secret, time.Now()))
+	}
+
+	utils.InfoLogger.Infof("Sending %s request to %s", method, url)
+	resp, err := outboundHTTPClient.Do(req)
+	if errors.Is(err, utils.ErrForbiddenAddress) {
+		return 0, "", fmt.Errorf("request failed: %w: %w", err, asynq.SkipRetry)
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponseBody))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, string(respBody), nil
+	}
+
+	err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		err = fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	return resp.StatusCode, string(respBody), err
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/troneras/ghost-listmonk-connector/database"
+	"github.com/troneras/ghost-listmonk-connector/models"
+)
+
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// idempotencyStore is what the executor needs from the IdempotencyStore
+type idempotencyStore interface {
+	Get(executionID string, actionIndex int, key string) (string, error)
+	Save(executionID string, actionIndex int, key string, result string) error
+	Delete(executionID string, actionIndex int, key string) error
+	Clear(executionID string, actionIndex int) error
+	HasPendingStep(key string, result string) (bool, error)
+}
+
+// IdempotencyStore records the side effects each action step of an
+// execution has completed, so a retried task resumes after them instead of
+// repeating them.
+type IdempotencyStore struct {
+	db *sql.DB
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{db: database.GetDB()}
+}
+
+// Get returns the result recorded when the step completed key
+func (s *IdempotencyStore) Get(executionID string, actionIndex int, key string) (string, error) {
+	var result string
+	err := s.db.QueryRow(`
+		SELECT result FROM son_action_idempotency_keys
+		WHERE son_execution_log_id = ? AND action_index = ? AND idempotency_key = ?
+	`, executionID, actionIndex, key).Scan(&result)
+	if err == sql.ErrNoRows {
+		return "", ErrIdempotencyKeyNotFound
+	}
+	return result, err
+}
+
+// Save records that the step completed key. The first result recorded for a
+// key is kept.
+func (s *IdempotencyStore) Save(executionID string, actionIndex int, key string, result string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO son_action_idempotency_keys (son_execution_log_id, action_index, idempotency_key, result)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE result = result
+	`, executionID, actionIndex, key, result)
+	return err
+}
+
+// Delete forgets a single key of the step
+func (s *IdempotencyStore) Delete(executionID string, actionIndex int, key string) error {
+	_, err := s.db.Exec(`
+		DELETE FROM son_action_idempotency_keys
+		WHERE son_execution_log_id = ? AND action_index = ? AND idempotency_key = ?
+	`, executionID, actionIndex, key)
+	return err
+}
+
+// ClearStarted forgets the side effects of a step that were started but may
+// not have completed, so retrying the step by hand performs them again
+func (s *IdempotencyStore) ClearStarted(executionID string, actionIndex int) error {
+	_, err := s.db.Exec(`
+		DELETE FROM son_action_idempotency_keys
+		WHERE son_execution_log_id = ? AND action_index = ? AND idempotency_key LIKE ?
+	`, executionID, actionIndex, "%"+strings.ReplaceAll(startedKeySuffix, "_", `\_`))
+	return err
+}
+
+// Clear forgets the side effects of a step, once they have been undone, so
+// the next attempt performs them again
+func (s *IdempotencyStore) Clear(executionID string, actionIndex int) error {
+	_, err := s.db.Exec(`
+		DELETE FROM son_action_idempotency_keys
+		WHERE son_execution_log_id = ? AND action_index = ?
+	`, executionID, actionIndex)
+	return err
+}
+
+// HasPendingStep reports whether a step that has not finished yet recorded
+// result for key, such as a campaign created by an attempt that will be
+// retried
+func (s *IdempotencyStore) HasPendingStep(key string, result string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM son_action_idempotency_keys k
+		JOIN son_execution_steps st ON st.son_execution_log_id = k.son_execution_log_id AND st.action_index = k.action_index
+		WHERE k.idempotency_key = ? AND k.result = ? AND st.status IN (?, ?, ?)
+	`, key, result, models.StatePending, models.StateQueued, models.StateRunning).Scan(&count)
+	return count > 0, err
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/troneras/ghost-listmonk-connector/database"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+var (
+	ErrListMappingNotFound = errors.New("list mapping not found")
+	ErrListMappingExists   = errors.New("list mapping already exists")
+)
+
+const listMappingColumns = "id, user_id, source_type, source_value, list_id, created_at, updated_at"
+
+type ListMappingService struct {
+	db *sql.DB
+}
+
+func NewListMappingService() *ListMappingService {
+	return &ListMappingService{db: database.GetDB()}
+}
+
+func (s *ListMappingService) List(userID string) ([]models.ListMapping, error) {
+	rows, err := s.db.Query("SELECT "+listMappingColumns+" FROM list_mappings WHERE user_id = ? ORDER BY source_type, source_value", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mappings []models.ListMapping
+	for rows.Next() {
+		mapping, err := scanListMapping(rows)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, *mapping)
+	}
+
+	return mappings, rows.Err()
+}
+
+func (s *ListMappingService) Get(id string, userID string) (*models.ListMapping, error) {
+	mapping, err := scanListMapping(s.db.QueryRow("SELECT "+listMappingColumns+" FROM list_mappings WHERE id = ? AND user_id = ?", id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrListMappingNotFound
+	}
+	return mapping, err
+}
+
+func (s *ListMappingService) Create(mapping *models.ListMapping) error {
+	now := time.Now()
+	mapping.ID = utils.GenerateUUID()
+	mapping.CreatedAt = now
+	mapping.UpdatedAt = now
+
+	_, err := s.db.Exec("INSERT INTO list_mappings (id, user_id, source_type, source_value, list_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		mapping.ID, mapping.UserID, mapping.SourceType, mapping.SourceValue, mapping.ListID, now, now)
+	if isDuplicateEntry(err) {
+		return ErrListMappingExists
+	}
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to create list mapping for user %s: %v", mapping.UserID, err)
+		return err
+	}
+
+	utils.InfoLogger.Infof("Created list mapping %s: %s %s -> list %d", mapping.ID, mapping.SourceType, mapping.SourceValue, mapping.ListID)
+	return nil
+}
+
+func (s *ListMappingService) Update(mapping *models.ListMapping) error {
+	mapping.UpdatedAt = time.Now()
+
+	result, err := s.db.Exec("UPDATE list_mappings SET source_type = ?, source_value = ?, list_id = ?, updated_at = ? WHERE id = ? AND user_id = ?",
+		mapping.SourceType, mapping.SourceValue, mapping.ListID, mapping.UpdatedAt, mapping.ID, mapping.UserID)
+	if isDuplicateEntry(err) {
+		return ErrListMappingExists
+	}
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to update list mapping %s: %v", mapping.ID, err)
+		return err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return ErrListMappingNotFound
+	}
+
+	return nil
+}
+
+func (s *ListMappingService) Delete(id string, userID string) error {
+	result, err := s.db.Exec("DELETE FROM list_mappings WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to delete list mapping %s: %v", id, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrListMappingNotFound
+	}
+
+	utils.InfoLogger.Infof("Deleted list mapping %s for user %s", id, userID)
+	return nil
+}
+
+func scanListMapping(row rowScanner) (*models.ListMapping, error) {
+	var mapping models.ListMapping
+	err := row.Scan(&mapping.ID, &mapping.UserID, &mapping.SourceType, &mapping.SourceValue, &mapping.ListID, &mapping.CreatedAt, &mapping.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -3,9 +3,13 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/troneras/ghost-listmonk-connector/utils"
 )
@@ -20,6 +24,26 @@ type ListmonkTemplate struct {
 	Name string `json:"name"`
 }
 
+type ListmonkSubscriber struct {
+	ID      int                      `json:"id"`
+	Email   string                   `json:"email"`
+	Name    string                   `json:"name"`
+	Status  string                   `json:"status"`
+	Attribs map[string]interface{}   `json:"attribs"`
+	Lists   []ListmonkSubscriberList `json:"lists"`
+}
+
+type ListmonkSubscriberList struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	SubscriptionStatus string `json:"subscription_status"`
+}
+
+var (
+	ErrSubscriberNotFound = errors.New("subscriber not found")
+	ErrCampaignNotFound   = errors.New("campaign not found")
+)
+
 type ListmonkClient struct {
 	baseURL string
 	client  *http.Client
@@ -107,13 +131,95 @@ func (c *ListmonkClient) SendTransactionalEmail(templateID int, subscriberEmail
 	return nil
 }
 
-func (c *ListmonkClient) ManageSubscriber(email string, name string, status string, lists []int, attributes map[string]interface{}) error {
+// SubscriberData is the subscriber state the connector wants in Listmonk
+type SubscriberData struct {
+	Email      string
+	Name       string
+	Status     string
+	Lists      []int
+	Attributes map[string]interface{}
+}
+
+// ListMode controls how the lists of an existing subscriber are updated
+type ListMode string
+
+const (
+	ListModeMerge   ListMode = "merge"
+	ListModeReplace ListMode = "replace"
+)
+
+// ManageSubscriber creates the subscriber, or updates it when it already
+// exists. lookupEmails are tried in order to find the existing subscriber,
+// which lets a Ghost email change follow the old address.
+func (c *ListmonkClient) ManageSubscriber(subscriber SubscriberData, lookupEmails []string, listMode ListMode) error {
+	for _, email := range lookupEmails {
+		if email == "" {
+			continue
+		}
+
+		existing, err := c.GetSubscriberByEmail(email)
+		if err == ErrSubscriberNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		utils.InfoLogger.Infof("Found existing subscriber %d for %s", existing.ID, email)
+		return c.UpdateSubscriber(existing.ID, mergeSubscriber(existing, subscriber, listMode))
+	}
+
+	return c.CreateSubscriber(subscriber)
+}
+
+// mergeSubscriber combines the existing Listmonk subscriber with the new
+// data. Listmonk replaces attributes and lists on update, so attributes are
+// merged and, in merge mode, every current list is kept whatever its status.
+// Listmonk deletes the subscriptions missing from the update, which would
+// erase an unsubscribe, and keeps the status of those that already exist.
+func mergeSubscriber(existing *ListmonkSubscriber, subscriber SubscriberData, listMode ListMode) SubscriberData {
+	attributes := make(map[string]interface{}, len(existing.Attribs)+len(subscriber.Attributes))
+	for k, v := range existing.Attribs {
+		attributes[k] = v
+	}
+	for k, v := range subscriber.Attributes {
+		attributes[k] = v
+	}
+	subscriber.Attributes = attributes
+
+	// Never re-enable a subscriber that was blocklisted in Listmonk
+	if existing.Status == "blocklisted" {
+		subscriber.Status = existing.Status
+	}
+
+	if listMode != ListModeReplace {
+		seen := make(map[int]bool)
+		lists := []int{}
+		for _, list := range existing.Lists {
+			if !seen[list.ID] {
+				seen[list.ID] = true
+				lists = append(lists, list.ID)
+			}
+		}
+		for _, id := range subscriber.Lists {
+			if !seen[id] {
+				seen[id] = true
+				lists = append(lists, id)
+			}
+		}
+		subscriber.Lists = lists
+	}
+
+	return subscriber
+}
+
+func (c *ListmonkClient) CreateSubscriber(subscriber SubscriberData) error {
 	payload := map[string]interface{}{
-		"email":                    email,
-		"name":                     name,
-		"status":                   status,
-		"lists":                    lists,
-		"attribs":                  attributes,
+		"email":                    subscriber.Email,
+		"name":                     subscriber.Name,
+		"status":                   subscriber.Status,
+		"lists":                    subscriber.Lists,
+		"attribs":                  subscriber.Attributes,
 		"preconfirm_subscriptions": true,
 	}
 
@@ -127,8 +233,8 @@ func (c *ListmonkClient) ManageSubscriber(email string, name string, status stri
 
 	resp, err := c.client.Post(c.baseURL+"/api/subscribers", "application/json", bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		utils.ErrorLogger.Errorf("Failed to manage subscriber: %v", err)
-		return fmt.Errorf("failed to manage subscriber: %w", err)
+		utils.ErrorLogger.Errorf("Failed to create subscriber: %v", err)
+		return fmt.Errorf("failed to create subscriber: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -138,10 +244,159 @@ func (c *ListmonkClient) ManageSubscriber(email string, name string, status stri
 		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	utils.InfoLogger.Infof("Managed subscriber %s with status %s and attributes %v", email, status, attributes)
+	utils.InfoLogger.Infof("Created subscriber %s with status %s and attributes %v", subscriber.Email, subscriber.Status, subscriber.Attributes)
+	return nil
+}
+
+func (c *ListmonkClient) UpdateSubscriber(id int, subscriber SubscriberData) error {
+	payload := map[string]interface{}{
+		"email":                    subscriber.Email,
+		"name":                     subscriber.Name,
+		"status":                   subscriber.Status,
+		"lists":                    subscriber.Lists,
+		"attribs":                  subscriber.Attributes,
+		"preconfirm_subscriptions": true,
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.put(fmt.Sprintf("%s/api/subscribers/%d", c.baseURL, id), jsonPayload)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to update subscriber: %v", err)
+		return fmt.Errorf("failed to update subscriber: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		utils.ErrorLogger.Errorf("Unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Updated subscriber %d (%s) with lists %v", id, subscriber.Email, subscriber.Lists)
+	return nil
+}
+
+// GetSubscriberByEmail looks a subscriber up by exact email address
+func (c *ListmonkClient) GetSubscriberByEmail(email string) (*ListmonkSubscriber, error) {
+	// Listmonk keeps emails as they were entered, so match them regardless of case
+	query := fmt.Sprintf("LOWER(subscribers.email) = '%s'", strings.ReplaceAll(strings.ToLower(email), "'", "''"))
+	resp, err := c.client.Get(c.baseURL + "/api/subscribers?per_page=1&query=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching subscriber: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Results []ListmonkSubscriber `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if len(result.Data.Results) == 0 {
+		return nil, ErrSubscriberNotFound
+	}
+	return &result.Data.Results[0], nil
+}
+
+// AddSubscriberToLists subscribes an existing subscriber to the given lists
+func (c *ListmonkClient) AddSubscriberToLists(id int, lists []int) error {
+	return c.updateSubscriberLists(id, "add", lists)
+}
+
+// RemoveSubscriberFromLists removes a subscriber from the given lists
+func (c *ListmonkClient) RemoveSubscriberFromLists(id int, lists []int) error {
+	return c.updateSubscriberLists(id, "remove", lists)
+}
+
+// UnsubscribeSubscriber marks a subscriber as unsubscribed on the given lists
+func (c *ListmonkClient) UnsubscribeSubscriber(id int, lists []int) error {
+	return c.updateSubscriberLists(id, "unsubscribe", lists)
+}
+
+func (c *ListmonkClient) updateSubscriberLists(id int, action string, lists []int) error {
+	payload := map[string]interface{}{
+		"ids":             []int{id},
+		"action":          action,
+		"target_list_ids": lists,
+	}
+	if action == "add" {
+		payload["status"] = "confirmed"
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.put(c.baseURL+"/api/subscribers/lists", jsonPayload)
+	if err != nil {
+		return fmt.Errorf("failed to %s subscriber lists: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Applied %s to subscriber %d for lists %v", action, id, lists)
+	return nil
+}
+
+// BlocklistSubscriber blocklists a subscriber, unsubscribing them from all lists
+func (c *ListmonkClient) BlocklistSubscriber(id int) error {
+	resp, err := c.put(fmt.Sprintf("%s/api/subscribers/%d/blocklist", c.baseURL, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to blocklist subscriber: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Blocklisted subscriber %d", id)
+	return nil
+}
+
+func (c *ListmonkClient) DeleteSubscriber(id int) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/subscribers/%d", c.baseURL, id), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscriber: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Deleted subscriber %d", id)
 	return nil
 }
 
+// ConnectorCampaignTag marks the campaigns the connector creates, so those
+// left behind can be told apart from campaigns made in Listmonk itself
+const ConnectorCampaignTag = "ghost-listmonk-connector"
+
 func (c *ListmonkClient) CreateCampaign(name string, subject string, lists []int, templateID int, sendAt string, body string, contentType string) (int, error) {
 	payload := map[string]interface{}{
 		"name":         name,
@@ -151,6 +406,7 @@ func (c *ListmonkClient) CreateCampaign(name string, subject string, lists []int
 		"send_at":      sendAt,
 		"content_type": contentType,
 		"body":         body,
+		"tags":         []string{ConnectorCampaignTag},
 	}
 
 	jsonPayload, err := json.Marshal(payload)
@@ -185,6 +441,215 @@ func (c *ListmonkClient) CreateCampaign(name string, subject string, lists []int
 	return result.Data.ID, nil
 }
 
+type ListmonkCampaign struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Subject     string         `json:"subject"`
+	FromEmail   string         `json:"from_email"`
+	Body        string         `json:"body"`
+	AltBody     string         `json:"altbody"`
+	ContentType string         `json:"content_type"`
+	TemplateID  int            `json:"template_id"`
+	Messenger   string         `json:"messenger"`
+	Status      string         `json:"status"`
+	SendAt      *string        `json:"send_at"`
+	Lists       []ListmonkList `json:"lists"`
+	Tags        []string       `json:"tags"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Sent        int            `json:"sent"`
+	Views       int            `json:"views"`
+	Clicks      int            `json:"clicks"`
+	Bounces     int            `json:"bounces"`
+}
+
+func (c *ListmonkClient) GetCampaign(id int) (*ListmonkCampaign, error) {
+	resp, err := c.client.Get(fmt.Sprintf("%s/api/campaigns/%d", c.baseURL, id))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching campaign: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrCampaignNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data ListmonkCampaign `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &result.Data, nil
+}
+
+// ListCampaigns returns every campaign with the given status, going through
+// all the pages Listmonk returns them in
+func (c *ListmonkClient) ListCampaigns(status string) ([]ListmonkCampaign, error) {
+	const perPage = 100
+	var campaigns []ListmonkCampaign
+	for page := 1; ; page++ {
+		resp, err := c.client.Get(fmt.Sprintf("%s/api/campaigns?status=%s&page=%d&per_page=%d&no_body=true", c.baseURL, url.QueryEscape(status), page, perPage))
+		if err != nil {
+			return nil, fmt.Errorf("error fetching campaigns: %w", err)
+		}
+
+		var result struct {
+			Data struct {
+				Results []ListmonkCampaign `json:"results"`
+				Total   int                `json:"total"`
+			} `json:"data"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding response: %w", err)
+		}
+
+		campaigns = append(campaigns, result.Data.Results...)
+		if len(result.Data.Results) < perPage || len(campaigns) >= result.Data.Total {
+			return campaigns, nil
+		}
+	}
+}
+
+// campaignRequest turns a fetched campaign back into the request body
+// Listmonk expects when updating or test sending it.
+func campaignRequest(campaign *ListmonkCampaign) map[string]interface{} {
+	lists := make([]int, 0, len(campaign.Lists))
+	for _, list := range campaign.Lists {
+		lists = append(lists, list.ID)
+	}
+
+	payload := map[string]interface{}{
+		"name":         campaign.Name,
+		"subject":      campaign.Subject,
+		"from_email":   campaign.FromEmail,
+		"lists":        lists,
+		"body":         campaign.Body,
+		"altbody":      campaign.AltBody,
+		"content_type": campaign.ContentType,
+		"template_id":  campaign.TemplateID,
+		"messenger":    campaign.Messenger,
+	}
+	// Listmonk replaces the tags on update, which would drop the marker tag
+	if campaign.Tags != nil {
+		payload["tags"] = campaign.Tags
+	}
+	if campaign.SendAt != nil {
+		payload["send_at"] = *campaign.SendAt
+	}
+	return payload
+}
+
+// SendCampaignTest sends the campaign to the given addresses through
+// Listmonk's test endpoint. The addresses must exist as subscribers.
+func (c *ListmonkClient) SendCampaignTest(id int, emails []string) error {
+	campaign, err := c.GetCampaign(id)
+	if err != nil {
+		return err
+	}
+
+	payload := campaignRequest(campaign)
+	payload["subscribers"] = emails
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.client.Post(fmt.Sprintf("%s/api/campaigns/%d/test", c.baseURL, id), "application/json", bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to send campaign test: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Sent test of campaign %d to %v", id, emails)
+	return nil
+}
+
+// RescheduleCampaign changes the send_at of a draft campaign
+func (c *ListmonkClient) RescheduleCampaign(id int, sendAt time.Time) error {
+	return c.updateCampaign(id, map[string]interface{}{"send_at": sendAt.UTC().Format(time.RFC3339)})
+}
+
+// UpdateCampaignContent replaces the subject and body of a campaign that has
+// not started sending yet
+func (c *ListmonkClient) UpdateCampaignContent(id int, subject string, body string) error {
+	return c.updateCampaign(id, map[string]interface{}{"subject": subject, "body": body})
+}
+
+// updateCampaign applies changes on top of the current campaign, since
+// Listmonk expects the whole campaign on update.
+func (c *ListmonkClient) updateCampaign(id int, changes map[string]interface{}) error {
+	campaign, err := c.GetCampaign(id)
+	if err != nil {
+		return err
+	}
+
+	payload := campaignRequest(campaign)
+	for k, v := range changes {
+		payload[k] = v
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.put(fmt.Sprintf("%s/api/campaigns/%d", c.baseURL, id), jsonPayload)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Updated campaign %d", id)
+	return nil
+}
+
+func (c *ListmonkClient) DeleteCampaign(id int) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/campaigns/%d", c.baseURL, id), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrCampaignNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	utils.InfoLogger.Infof("Deleted campaign %d", id)
+	return nil
+}
+
 // In services/listmonk_client.go
 
 func (c *ListmonkClient) UpdateCampaignStatus(id int, status string) error {
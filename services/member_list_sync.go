@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeSyncMemberLists = "sync_member_lists"
+
+// EnqueueMemberListSync queues a task applying the account's list mappings to
+// a member payload. Nothing is queued when the account has no mappings.
+func (e *SonExecutor) EnqueueMemberListSync(payload ProcessWebhookPayload) error {
+	mappings, err := e.listMappings.List(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list list mappings: %w", err)
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal list sync payload: %w", err)
+	}
+
+	info, err := e.asyncClient.Enqueue(asynq.NewTask(TypeSyncMemberLists, data), asynq.MaxRetry(5))
+	if err != nil {
+		return fmt.Errorf("failed to enqueue list sync: %w", err)
+	}
+
+	utils.InfoLogger.Infof("Enqueued list sync for webhook %s: task=%s", payload.WebhookLogID, info.ID)
+	return nil
+}
+
+func (e *SonExecutor) handleSyncMemberLists(ctx context.Context, t *asynq.Task) error {
+	var payload ProcessWebhookPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	mappings, err := e.listMappings.List(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list list mappings: %w", err)
+	}
+
+	add, remove := models.ResolveListChanges(mappings, payload.Data)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	return e.syncMemberLists(payload.Data, add, remove)
+}
+
+// syncMemberLists adds the subscriber to the lists they are not on yet and
+// removes them from the given lists. Lists the subscriber already has, in any
+// status, are left alone so an unsubscribe in Listmonk is not undone.
+func (e *SonExecutor) syncMemberLists(data map[string]interface{}, add []int, remove []int) error {
+	member, _ := data["member"].(map[string]interface{})
+	current, _ := member["current"].(map[string]interface{})
+	previous, _ := member["previous"].(map[string]interface{})
+
+	email, _ := current["email"].(string)
+	if email == "" {
+		return fmt.Errorf("invalid or missing email: %w", asynq.SkipRetry)
+	}
+
+	subscriber, err := e.listmonkClient.GetSubscriberByEmail(email)
+	if err == ErrSubscriberNotFound {
+		// The member may have just changed their email
+		if previousEmail, _ := previous["email"].(string); previousEmail != "" && previousEmail != email {
+			subscriber, err = e.listmonkClient.GetSubscriberByEmail(previousEmail)
+		}
+	}
+
+	if err == ErrSubscriberNotFound {
+		if len(add) == 0 {
+			return nil
+		}
+		name, _ := current["name"].(string)
+		utils.InfoLogger.Infof("Creating subscriber %s on mapped lists %v", email, add)
+		return e.listmonkClient.CreateSubscriber(SubscriberData{
+			Email:      email,
+			Name:       name,
+			Status:     "enabled",
+			Lists:      add,
+			Attributes: map[string]interface{}{},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	onList := make(map[int]bool, len(subscriber.Lists))
+	for _, list := range subscriber.Lists {
+		onList[list.ID] = true
+	}
+
+	var missing []int
+	for _, id := range add {
+		if !onList[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		if err := e.listmonkClient.AddSubscriberToLists(subscriber.ID, missing); err != nil {
+			return err
+		}
+	}
+
+	var leaving []int
+	for _, id := range remove {
+		if onList[id] {
+			leaving = append(leaving, id)
+		}
+	}
+	if len(leaving) > 0 {
+		if err := e.listmonkClient.RemoveSubscriberFromLists(subscriber.ID, leaving); err != nil {
+			return err
+		}
+	}
+
+	utils.InfoLogger.Infof("Synced lists for subscriber %s: added %v, removed %v", subscriber.Email, missing, leaving)
+	return nil
+}
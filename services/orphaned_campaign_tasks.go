@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeSweepOrphanedCampaigns = "sweep_orphaned_campaigns"
+
+// compensateCampaign undoes a create_campaign action that created its
+// campaign but failed for good on a later call, deleting the draft instead of
+// leaving it orphaned in Listmonk. The step forgets the campaign, so retrying
+// its dead letter starts over. A campaign that cannot be deleted is left to
+// the orphaned campaign sweep.
+func (e *SonExecutor) compensateCampaign(t *asynq.Task, executionID string, campaignID int) {
+	if err := e.listmonkClient.DeleteCampaign(campaignID); err != nil && err != ErrCampaignNotFound {
+		utils.ErrorLogger.Errorf("Failed to delete campaign %d of failed execution %s: %v", campaignID, executionID, err)
+		e.executionLogger.LogActionExecution(executionID, "delete_campaign", models.StateFailed, err.Error())
+		return
+	}
+
+	if err := e.approvals.CancelForCampaign(campaignID); err != nil {
+		utils.ErrorLogger.Errorf("Failed to cancel approval of campaign %d: %v", campaignID, err)
+	}
+	if err := e.campaignStats.MarkDeleted(campaignID); err != nil {
+		utils.ErrorLogger.Errorf("Failed to mark campaign %d as deleted: %v", campaignID, err)
+	}
+	if step, ok := parseActionStep(t); ok {
+		if err := e.idempotency.Clear(step.ExecutionID, *step.ActionIndex); err != nil {
+			utils.ErrorLogger.Errorf("Failed to clear step %d of execution %s: %v", *step.ActionIndex, step.ExecutionID, err)
+		}
+	}
+
+	utils.InfoLogger.Infof("Deleted campaign %d of failed execution %s", campaignID, executionID)
+	e.executionLogger.LogCampaignAction(executionID, "delete_campaign", campaignID)
+}
+
+// orphanedCampaignSweep is the payload of the sweep task
+type orphanedCampaignSweep struct {
+	// OlderThan is how long a draft is given to be scheduled or approved
+	OlderThan string `json:"older_than"`
+	// Delete removes the orphaned campaigns instead of only reporting them
+	Delete bool `json:"delete"`
+}
+
+// ScheduleOrphanedCampaignSweep looks for campaigns the connector created
+// but never scheduled at the given interval. They are reported, and deleted
+// as well when cleanup is set.
+func (e *SonExecutor) ScheduleOrphanedCampaignSweep(interval time.Duration, olderThan time.Duration, cleanup bool) error {
+	if interval <= 0 {
+		return fmt.Errorf("orphaned campaign sweep interval must be positive, got %s", interval)
+	}
+	payload, err := json.Marshal(orphanedCampaignSweep{OlderThan: olderThan.String(), Delete: cleanup})
+	if err != nil {
+		return err
+	}
+	_, err = e.scheduler.Register(fmt.Sprintf("@every %s", interval), asynq.NewTask(TypeSweepOrphanedCampaigns, payload),
+		asynq.Queue("low"), asynq.MaxRetry(0), asynq.Unique(interval))
+	return err
+}
+
+func (e *SonExecutor) handleSweepOrphanedCampaigns(ctx context.Context, t *asynq.Task) error {
+	var sweep orphanedCampaignSweep
+	if err := json.Unmarshal(t.Payload(), &sweep); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+	olderThan, err := utils.ParseDuration(sweep.OlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid older_than: %v: %w", err, asynq.SkipRetry)
+	}
+
+	drafts, err := e.listmonkClient.ListCampaigns("draft")
+	if err != nil {
+		return fmt.Errorf("failed to list draft campaigns: %w", err)
+	}
+
+	orphaned, deleted := 0, 0
+	for _, campaign := range drafts {
+		if !e.isOrphanedCampaign(campaign, time.Now().Add(-olderThan)) {
+			continue
+		}
+		orphaned++
+
+		if !sweep.Delete {
+			utils.InfoLogger.Infof("Campaign %d (%s) was created %s but never scheduled", campaign.ID, campaign.Name, campaign.CreatedAt.Format(time.RFC3339))
+			continue
+		}
+		if err := e.listmonkClient.DeleteCampaign(campaign.ID); err != nil && err != ErrCampaignNotFound {
+			utils.ErrorLogger.Errorf("Failed to delete orphaned campaign %d: %v", campaign.ID, err)
+			continue
+		}
+		if err := e.campaignStats.MarkDeleted(campaign.ID); err != nil {
+			utils.ErrorLogger.Errorf("Failed to mark campaign %d as deleted: %v", campaign.ID, err)
+		}
+		deleted++
+	}
+
+	if orphaned > 0 {
+		utils.InfoLogger.Infof("Found %d orphaned campaigns, deleted %d", orphaned, deleted)
+	}
+	return nil
+}
+
+// isOrphanedCampaign reports whether a draft was created by the connector
+// before the cutoff and nothing is going to schedule it: it is not waiting
+// for approval, and the action that created it is not being retried.
+func (e *SonExecutor) isOrphanedCampaign(campaign ListmonkCampaign, cutoff time.Time) bool {
+	if !hasTag(campaign.Tags, ConnectorCampaignTag) || campaign.CreatedAt.After(cutoff) {
+		return false
+	}
+
+	pending, err := e.approvals.IsPending(campaign.ID)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to check approval of campaign %d: %v", campaign.ID, err)
+		return false
+	}
+	if pending {
+		return false
+	}
+
+	retrying, err := e.idempotency.HasPendingStep(idempotencyCampaignCreated, strconv.Itoa(campaign.ID))
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to check the step of campaign %d: %v", campaign.ID, err)
+		return false
+	}
+	return !retrying
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/troneras/ghost-listmonk-connector/database"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+var ErrPostCampaignNotFound = errors.New("post campaign not found")
+
+const postCampaignColumns = "id, user_id, son_id, action_index, post_id, campaign_id, created_at, updated_at"
+
+type PostCampaignService struct {
+	db *sql.DB
+}
+
+func NewPostCampaignService() *PostCampaignService {
+	return &PostCampaignService{db: database.GetDB()}
+}
+
+// Get returns the campaign a Son action created for a post
+func (s *PostCampaignService) Get(sonID string, actionIndex int, postID string) (*models.PostCampaign, error) {
+	postCampaign, err := scanPostCampaign(s.db.QueryRow("SELECT "+postCampaignColumns+" FROM post_campaigns WHERE son_id = ? AND action_index = ? AND post_id = ?",
+		sonID, actionIndex, postID))
+	if err == sql.ErrNoRows {
+		return nil, ErrPostCampaignNotFound
+	}
+	return postCampaign, err
+}
+
+// ListByPost returns every campaign created for a post
+func (s *PostCampaignService) ListByPost(userID string, postID string) ([]models.PostCampaign, error) {
+	rows, err := s.db.Query("SELECT "+postCampaignColumns+" FROM post_campaigns WHERE user_id = ? AND post_id = ?", userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postCampaigns []models.PostCampaign
+	for rows.Next() {
+		postCampaign, err := scanPostCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		postCampaigns = append(postCampaigns, *postCampaign)
+	}
+
+	return postCampaigns, rows.Err()
+}
+
+// Save records the campaign of a post, replacing a previous campaign that
+// was deleted or cancelled before it was sent
+func (s *PostCampaignService) Save(postCampaign *models.PostCampaign) error {
+	now := time.Now()
+	postCampaign.ID = utils.GenerateUUID()
+	postCampaign.CreatedAt = now
+	postCampaign.UpdatedAt = now
+
+	_, err := s.db.Exec(`
+		INSERT INTO post_campaigns (id, user_id, son_id, action_index, post_id, campaign_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE campaign_id = VALUES(campaign_id), updated_at = VALUES(updated_at)
+	`, postCampaign.ID, postCampaign.UserID, postCampaign.SonID, postCampaign.ActionIndex, postCampaign.PostID, postCampaign.CampaignID, now, now)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to save campaign %d for post %s: %v", postCampaign.CampaignID, postCampaign.PostID, err)
+		return err
+	}
+
+	utils.InfoLogger.Infof("Post %s is mapped to campaign %d", postCampaign.PostID, postCampaign.CampaignID)
+	return nil
+}
+
+func scanPostCampaign(row rowScanner) (*models.PostCampaign, error) {
+	var postCampaign models.PostCampaign
+	err := row.Scan(&postCampaign.ID, &postCampaign.UserID, &postCampaign.SonID, &postCampaign.ActionIndex, &postCampaign.PostID,
+		&postCampaign.CampaignID, &postCampaign.CreatedAt, &postCampaign.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &postCampaign, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeSyncPostCampaigns = "sync_post_campaigns"
+
+// updatePostCampaign reuses the campaign the action already created for the
+// post. It returns the ID of the updated campaign, or 0 when there is none
+// left and a new one has to be created. A campaign that has started sending
+// is refused.
+func (e *SonExecutor) updatePostCampaign(owner actionOwner, postID string, subject string, body string) (int, error) {
+	postCampaign, err := e.postCampaigns.Get(owner.SonID, owner.ActionIndex, postID)
+	if err == ErrPostCampaignNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get campaign of post %s: %w", postID, err)
+	}
+
+	campaign, err := e.listmonkClient.GetCampaign(postCampaign.CampaignID)
+	if err == ErrCampaignNotFound {
+		// Deleted after a rejection, an expiry or an unpublish
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch campaign.Status {
+	case "draft", "scheduled":
+		if err := e.listmonkClient.UpdateCampaignContent(campaign.ID, subject, body); err != nil {
+			return 0, err
+		}
+		utils.InfoLogger.Infof("Updated campaign %d of post %s", campaign.ID, postID)
+		return campaign.ID, nil
+	case "cancelled":
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("post %s already has a sent campaign %d: %w", postID, campaign.ID, asynq.SkipRetry)
+	}
+}
+
+// EnqueuePostCampaignSync queues a task bringing the campaigns of an edited,
+// unpublished or deleted post in line with it. Nothing is queued when the
+// post has no campaign.
+func (e *SonExecutor) EnqueuePostCampaignSync(payload ProcessWebhookPayload) error {
+	postID := getPostID(payload.Data)
+	if postID == "" {
+		return nil
+	}
+
+	postCampaigns, err := e.postCampaigns.ListByPost(payload.UserID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to list post campaigns: %w", err)
+	}
+	if len(postCampaigns) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal post campaign sync payload: %w", err)
+	}
+
+	info, err := e.asyncClient.Enqueue(asynq.NewTask(TypeSyncPostCampaigns, data), asynq.MaxRetry(5))
+	if err != nil {
+		return fmt.Errorf("failed to enqueue post campaign sync: %w", err)
+	}
+
+	utils.InfoLogger.Infof("Enqueued post campaign sync for webhook %s: task=%s", payload.WebhookLogID, info.ID)
+	return nil
+}
+
+func (e *SonExecutor) handleSyncPostCampaigns(ctx context.Context, t *asynq.Task) error {
+	var payload ProcessWebhookPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	postID := getPostID(payload.Data)
+	postCampaigns, err := e.postCampaigns.ListByPost(payload.UserID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to list post campaigns: %w", err)
+	}
+
+	var errs []error
+	for _, postCampaign := range postCampaigns {
+		if err := e.syncPostCampaign(payload, postCampaign); err != nil {
+			utils.ErrorLogger.Errorf("Failed to sync campaign %d of post %s: %v", postCampaign.CampaignID, postID, err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncPostCampaign discards the campaign of an unpublished or deleted post,
+// and re-renders it from its action when the post was edited. Campaigns that
+// have started sending are left alone.
+func (e *SonExecutor) syncPostCampaign(payload ProcessWebhookPayload, postCampaign models.PostCampaign) error {
+	campaign, err := e.listmonkClient.GetCampaign(postCampaign.CampaignID)
+	if err == ErrCampaignNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if campaign.Status != "draft" && campaign.Status != "scheduled" {
+		return nil
+	}
+
+	if payload.Trigger == models.TriggerPostUnpublished || payload.Trigger == models.TriggerPostDeleted {
+		if err := e.listmonkClient.DeleteCampaign(campaign.ID); err != nil {
+			return err
+		}
+		if err := e.approvals.CancelForCampaign(campaign.ID); err != nil {
+			utils.ErrorLogger.Errorf("Failed to cancel approval of campaign %d: %v", campaign.ID, err)
+		}
+		utils.InfoLogger.Infof("Discarded campaign %d of post %s", campaign.ID, postCampaign.PostID)
+		return nil
+	}
+
+	son, err := e.sonStorage.Get(postCampaign.SonID)
+	if err != nil {
+		return fmt.Errorf("failed to get Son %s: %w", postCampaign.SonID, err)
+	}
+	if postCampaign.ActionIndex >= len(son.Actions) || son.Actions[postCampaign.ActionIndex].Type != models.ActionCreateCampaign {
+		utils.InfoLogger.Infof("Son %s no longer creates campaign %d, leaving it unchanged", son.ID, campaign.ID)
+		return nil
+	}
+
+	templateContext, err := models.NewTemplateContext(son.TemplateSon(), payload.Data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	subject, body, err := models.RenderCampaign(son.Actions[postCampaign.ActionIndex].Parameters, templateContext)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := e.listmonkClient.UpdateCampaignContent(campaign.ID, subject, body); err != nil {
+		return err
+	}
+
+	utils.InfoLogger.Infof("Updated campaign %d of edited post %s", campaign.ID, postCampaign.PostID)
+	return nil
+}
+
+// getPostID returns the Ghost post ID, falling back to the previous version
+// for post.deleted payloads whose current version is empty.
+func getPostID(data map[string]interface{}) string {
+	post, ok := data["post"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, version := range []string{"current", "previous"} {
+		if p, ok := post[version].(map[string]interface{}); ok {
+			if id, ok := p["id"].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+
+	return ""
+}
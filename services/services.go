@@ -15,6 +15,13 @@ type Services struct {
 	WebhookLogger      *WebhookLogger
 	SonExecutionLogger *SonExecutionLogger
 	RecentActivity     *RecentActivityService
+	SignatureVerifier  *WebhookSignatureVerifier
+	Deduplicator       *WebhookDeduplicator
+	ListMappings       *ListMappingService
+	CampaignApprovals  *CampaignApprovalService
+	PostCampaigns      *PostCampaignService
+	CampaignStats      *CampaignStatsService
+	DeadLetters        *DeadLetterService
 }
 
 func NewServices(config *utils.Config) (*Services, error) {
@@ -30,8 +37,45 @@ func NewServices(config *utils.Config) (*Services, error) {
 	sonExecutionLogger := NewSonExecutionLogger(config.RedisAddr)
 
 	recentActivity := NewRecentActivityService()
+	sonStorage := NewSonStorage(recentActivity)
+	webhookLogger := NewWebhookLogger()
+	listMappings := NewListMappingService()
+	campaignApprovals := NewCampaignApprovalService(listmonkClient)
+	postCampaigns := NewPostCampaignService()
+	campaignStats := NewCampaignStatsService()
+	idempotency := NewIdempotencyStore()
 
-	sonExecutor, err := NewSonExecutor(listmonkClient, config.RedisAddr, sonExecutionLogger)
+	sonExecutor, err := NewSonExecutor(listmonkClient, config.RedisAddr, sonExecutionLogger, sonStorage, webhookLogger, listMappings, campaignApprovals, postCampaigns, campaignStats, idempotency)
+	if err != nil {
+		return nil, err
+	}
+
+	campaignStatsInterval, err := utils.ParseDuration(config.CampaignStatsInterval)
+	if err != nil {
+		return nil, err
+	}
+	if err := sonExecutor.ScheduleCampaignStatsSync(campaignStatsInterval); err != nil {
+		return nil, err
+	}
+
+	sweepInterval, err := utils.ParseDuration(config.OrphanedCampaignSweepInterval)
+	if err != nil {
+		return nil, err
+	}
+	orphanedAge, err := utils.ParseDuration(config.OrphanedCampaignAge)
+	if err != nil {
+		return nil, err
+	}
+	if err := sonExecutor.ScheduleOrphanedCampaignSweep(sweepInterval, orphanedAge, config.OrphanedCampaignAction == "delete"); err != nil {
+		return nil, err
+	}
+
+	signatureTolerance, err := utils.ParseDuration(config.WebhookTimestampTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	dedupTTL, err := utils.ParseDuration(config.WebhookDedupTTL)
 	if err != nil {
 		return nil, err
 	}
@@ -40,12 +84,19 @@ func NewServices(config *utils.Config) (*Services, error) {
 		User:               userService,
 		MagicLink:          NewMagicLinkService(),
 		Email:              emailService,
-		SonStorage:         NewSonStorage(recentActivity),
+		SonStorage:         sonStorage,
 		SonExecutor:        sonExecutor,
 		Webhook:            webhookService,
 		ListmonkClient:     listmonkClient,
-		WebhookLogger:      NewWebhookLogger(),
+		WebhookLogger:      webhookLogger,
 		SonExecutionLogger: sonExecutionLogger,
 		RecentActivity:     recentActivity,
+		SignatureVerifier:  NewWebhookSignatureVerifier(config.RedisAddr, signatureTolerance),
+		Deduplicator:       NewWebhookDeduplicator(config.RedisAddr, dedupTTL),
+		ListMappings:       listMappings,
+		CampaignApprovals:  campaignApprovals,
+		PostCampaigns:      postCampaigns,
+		CampaignStats:      campaignStats,
+		DeadLetters:        NewDeadLetterService(config.RedisAddr, sonExecutionLogger, idempotency),
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,6 +15,11 @@ import (
 	"github.com/troneras/ghost-listmonk-connector/utils"
 )
 
+var (
+	ErrExecutionStepNotFound = errors.New("execution step not found")
+	ErrInvalidTransition     = errors.New("invalid state transition")
+)
+
 type SonExecutionLogger struct {
 	db    *sql.DB
 	redis *redis.Client
@@ -33,51 +39,93 @@ type SonStats struct {
 	Failure    int    `json:"failure"`
 }
 
-func (l *SonExecutionLogger) LogSonExecution(sonID, webhookLogID string, status string, errorMessage string) (string, error) {
+// LogSonExecution records a pending Son run, whose state then follows its
+// steps. memberEmail is the member the webhook is about, empty for post and
+// site triggers.
+func (l *SonExecutionLogger) LogSonExecution(sonID, webhookLogID string, memberEmail string) (string, error) {
 	executionID := utils.GenerateUUID()
 	_, err := l.db.Exec(`
-		INSERT INTO son_execution_logs (id, son_id, webhook_log_id, execution_status, error_message)
-		VALUES (?, ?, ?, ?, ?)
-	`, executionID, sonID, webhookLogID, status, errorMessage)
+		INSERT INTO son_execution_logs (id, son_id, webhook_log_id, member_email, execution_status, error_message)
+		VALUES (?, ?, ?, ?, ?, '')
+	`, executionID, sonID, webhookLogID, nullString(memberEmail), models.StatePending)
 	if err != nil {
 		return "", err
 	}
 	return executionID, nil
 }
 
-func (l *SonExecutionLogger) LogActionExecution(executionID string, actionType string, status string, errorMessage string) error {
+// LogSkippedSonExecution records a Son that matched the trigger but did not
+// run, together with the reason it was skipped.
+func (l *SonExecutionLogger) LogSkippedSonExecution(sonID, webhookLogID string, reason string) (string, error) {
+	executionID := utils.GenerateUUID()
+	_, err := l.db.Exec(`
+		INSERT INTO son_execution_logs (id, son_id, webhook_log_id, execution_status, error_message, skip_reason)
+		VALUES (?, ?, ?, ?, '', ?)
+	`, executionID, sonID, webhookLogID, models.StateSkipped, reason)
+	if err != nil {
+		return "", err
+	}
+	return executionID, nil
+}
+
+// LogActionExecution records an event in the history of an action, such as
+// a failed attempt. The state of the action itself is kept by its step.
+func (l *SonExecutionLogger) LogActionExecution(executionID string, actionType string, status models.ExecutionState, errorMessage string) error {
 	_, err := l.db.Exec(`
 		INSERT INTO son_execution_action_logs (id, son_execution_log_id, action_type, action_status, error_message)
 		VALUES (?, ?, ?, ?, ?)
 	`, utils.GenerateUUID(), executionID, actionType, status, errorMessage)
+	return err
+}
 
-	if status == "failure" {
-		l.UpdateSonExecutionStatus(executionID, "failure", errorMessage)
-	}
+// LogActionResponse records an action that called an external endpoint,
+// together with the status and body it answered with.
+func (l *SonExecutionLogger) LogActionResponse(executionID string, actionType string, status models.ExecutionState, errorMessage string, responseStatus int, responseBody string) error {
+	_, err := l.db.Exec(`
+		INSERT INTO son_execution_action_logs (id, son_execution_log_id, action_type, action_status, error_message, response_status, response_body)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, utils.GenerateUUID(), executionID, actionType, status, errorMessage, sql.NullInt64{Int64: int64(responseStatus), Valid: responseStatus != 0}, responseBody)
+	return err
+}
 
+// LogCampaignAction records an action that created or updated a Listmonk
+// campaign, so the campaign can be traced back to the execution.
+func (l *SonExecutionLogger) LogCampaignAction(executionID string, actionType string, campaignID int) error {
+	_, err := l.db.Exec(`
+		INSERT INTO son_execution_action_logs (id, son_execution_log_id, action_type, action_status, error_message, campaign_id)
+		VALUES (?, ?, ?, ?, '', ?)
+	`, utils.GenerateUUID(), executionID, actionType, models.StateSucceeded, campaignID)
 	return err
 }
 
-func (l *SonExecutionLogger) GetSonExecutionLogs(userID string, limit, offset int) ([]models.SonExecutionLog, int, error) {
+// GetSonExecutionLogs lists the executions of the user's Sons, optionally
+// only those of one member.
+func (l *SonExecutionLogger) GetSonExecutionLogs(userID string, memberEmail string, limit, offset int) ([]models.SonExecutionLog, int, error) {
+	where := "WHERE s.user_id = ?"
+	args := []interface{}{userID}
+	if memberEmail != "" {
+		where += " AND sel.member_email = ?"
+		args = append(args, memberEmail)
+	}
+
 	var total int
 	err := l.db.QueryRow(`
 		SELECT COUNT(*) 
 		FROM son_execution_logs sel
 		JOIN sons s ON sel.son_id = s.id
-		WHERE s.user_id = ?
-	`, userID).Scan(&total)
+		`+where, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	rows, err := l.db.Query(`
-		SELECT sel.id, sel.son_id, sel.webhook_log_id, sel.execution_status, sel.executed_at, sel.error_message
+		SELECT sel.id, sel.son_id, sel.webhook_log_id, COALESCE(sel.member_email, ''), sel.execution_status, sel.executed_at, sel.error_message, COALESCE(sel.skip_reason, '')
 		FROM son_execution_logs sel
 		JOIN sons s ON sel.son_id = s.id
-		WHERE s.user_id = ?
+		`+where+`
 		ORDER BY sel.executed_at DESC
 		LIMIT ? OFFSET ?
-	`, userID, limit, offset)
+	`, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -86,7 +134,7 @@ func (l *SonExecutionLogger) GetSonExecutionLogs(userID string, limit, offset in
 	var logs []models.SonExecutionLog
 	for rows.Next() {
 		var log models.SonExecutionLog
-		err := rows.Scan(&log.ID, &log.SonID, &log.WebhookLogID, &log.Status, &log.ExecutedAt, &log.ErrorMessage)
+		err := rows.Scan(&log.ID, &log.SonID, &log.WebhookLogID, &log.MemberEmail, &log.Status, &log.ExecutedAt, &log.ErrorMessage, &log.SkipReason)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -98,7 +146,7 @@ func (l *SonExecutionLogger) GetSonExecutionLogs(userID string, limit, offset in
 
 func (l *SonExecutionLogger) GetActionExecutionLogs(executionID string) ([]models.ActionExecutionLog, error) {
 	rows, err := l.db.Query(`
-		SELECT id, son_execution_log_id, action_type, action_status, executed_at, error_message
+		SELECT id, son_execution_log_id, action_type, action_status, executed_at, error_message, response_status, COALESCE(response_body, ''), campaign_id
 		FROM son_execution_action_logs
 		WHERE son_execution_log_id = ?
 		ORDER BY executed_at ASC
@@ -111,25 +159,187 @@ func (l *SonExecutionLogger) GetActionExecutionLogs(executionID string) ([]model
 	var logs []models.ActionExecutionLog
 	for rows.Next() {
 		var log models.ActionExecutionLog
-		err := rows.Scan(&log.ID, &log.ExecutionLogID, &log.ActionType, &log.Status, &log.ExecutedAt, &log.ErrorMessage)
+		var responseStatus, campaignID sql.NullInt64
+		err := rows.Scan(&log.ID, &log.ExecutionLogID, &log.ActionType, &log.Status, &log.ExecutedAt, &log.ErrorMessage, &responseStatus, &log.ResponseBody, &campaignID)
 		if err != nil {
 			return nil, err
 		}
+		if responseStatus.Valid {
+			status := int(responseStatus.Int64)
+			log.ResponseStatus = &status
+		}
+		if campaignID.Valid {
+			id := int(campaignID.Int64)
+			log.CampaignID = &id
+		}
 		logs = append(logs, log)
 	}
 
 	return logs, nil
 }
 
-func (l *SonExecutionLogger) UpdateSonExecutionStatus(executionID string, status string, errorMessage string) error {
+// RecordStep records an action queued as a step of an execution
+func (l *SonExecutionLogger) RecordStep(step *models.ExecutionStep) error {
+	step.ID = utils.GenerateUUID()
 	_, err := l.db.Exec(`
-        UPDATE son_execution_logs
-        SET execution_status = ?, error_message = ?
-        WHERE id = ?
-    `, status, errorMessage, executionID)
+		INSERT INTO son_execution_steps (id, son_execution_log_id, action_index, action_type, task_id, queue, status, scheduled_for)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, step.ID, step.ExecutionLogID, step.ActionIndex, step.ActionType, nullString(step.TaskID), step.Queue, step.Status, step.ScheduledFor)
 	return err
 }
 
+// TransitionStep moves a step of an execution to another state and updates
+// the state of the execution to match. It is the only place the state of an
+// action changes, and refuses the moves the state machine does not allow.
+func (l *SonExecutionLogger) TransitionStep(executionID string, actionIndex int, to models.ExecutionState, errorMessage string) error {
+	return l.updateExecution(executionID, func(tx *sql.Tx) error {
+		var stepID string
+		var from models.ExecutionState
+		err := tx.QueryRow(`
+			SELECT id, status FROM son_execution_steps
+			WHERE son_execution_log_id = ? AND action_index = ?
+			FOR UPDATE
+		`, executionID, actionIndex).Scan(&stepID, &from)
+		if err == sql.ErrNoRows {
+			return ErrExecutionStepNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !from.CanTransitionTo(to) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE son_execution_steps
+			SET status = ?, error_message = ?, finished_at = ?
+			WHERE id = ?
+		`, to, nullString(errorMessage), sql.NullTime{Time: time.Now(), Valid: to.IsFinal()}, stepID)
+		return err
+	})
+}
+
+// RefreshExecutionState recomputes the state of an execution from its steps
+func (l *SonExecutionLogger) RefreshExecutionState(executionID string) error {
+	return l.updateExecution(executionID, func(tx *sql.Tx) error { return nil })
+}
+
+// updateExecution runs update and then sets the state of the execution to
+// the aggregate of its steps. The execution is locked first, so steps
+// finishing at the same time each see the others.
+func (l *SonExecutionLogger) updateExecution(executionID string, update func(tx *sql.Tx) error) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRow(`SELECT id FROM son_execution_logs WHERE id = ? FOR UPDATE`, executionID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrExecutionStepNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := update(tx); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`
+		SELECT status, COALESCE(error_message, '')
+		FROM son_execution_steps
+		WHERE son_execution_log_id = ?
+		ORDER BY action_index ASC
+	`, executionID)
+	if err != nil {
+		return err
+	}
+	var states []models.ExecutionState
+	var errorMessage string
+	for rows.Next() {
+		var state models.ExecutionState
+		var stepError string
+		if err := rows.Scan(&state, &stepError); err != nil {
+			rows.Close()
+			return err
+		}
+		states = append(states, state)
+		// The execution reports the first action that failed
+		if state == models.StateFailed && errorMessage == "" {
+			errorMessage = stepError
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE son_execution_logs
+		SET execution_status = ?, error_message = ?
+		WHERE id = ?
+	`, models.AggregateExecutionState(states), errorMessage, executionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const executionStepColumns = `st.id, st.son_execution_log_id, sel.son_id, st.action_index, st.action_type, COALESCE(st.task_id, ''), st.queue, st.status, st.scheduled_for, st.finished_at, COALESCE(st.error_message, '')`
+
+// GetExecutionSteps lists the steps of an execution of one of the user's
+// Sons, in sequence order.
+func (l *SonExecutionLogger) GetExecutionSteps(userID string, executionID string) ([]models.ExecutionStep, error) {
+	return l.queryExecutionSteps(`
+		SELECT `+executionStepColumns+`
+		FROM son_execution_steps st
+		JOIN son_execution_logs sel ON st.son_execution_log_id = sel.id
+		JOIN sons s ON sel.son_id = s.id
+		WHERE st.son_execution_log_id = ? AND s.user_id = ?
+		ORDER BY st.scheduled_for ASC, st.action_index ASC
+	`, executionID, userID)
+}
+
+// ListQueuedMemberSteps lists the steps still waiting to run for a member
+// across the executions of the user's Sons.
+func (l *SonExecutionLogger) ListQueuedMemberSteps(userID string, memberEmail string) ([]models.ExecutionStep, error) {
+	return l.queryExecutionSteps(`
+		SELECT `+executionStepColumns+`
+		FROM son_execution_steps st
+		JOIN son_execution_logs sel ON st.son_execution_log_id = sel.id
+		JOIN sons s ON sel.son_id = s.id
+		WHERE s.user_id = ? AND sel.member_email = ? AND st.status = ?
+		ORDER BY st.scheduled_for ASC
+	`, userID, memberEmail, models.StateQueued)
+}
+
+func (l *SonExecutionLogger) queryExecutionSteps(query string, args ...interface{}) ([]models.ExecutionStep, error) {
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []models.ExecutionStep
+	for rows.Next() {
+		var step models.ExecutionStep
+		var finishedAt sql.NullTime
+		err := rows.Scan(&step.ID, &step.ExecutionLogID, &step.SonID, &step.ActionIndex, &step.ActionType, &step.TaskID, &step.Queue, &step.Status, &step.ScheduledFor, &finishedAt, &step.ErrorMessage)
+		if err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			step.FinishedAt = &finishedAt.Time
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, rows.Err()
+}
+
 func (l *SonExecutionLogger) GetSonStats(ctx context.Context, userID string, timeframe string) ([]SonStats, error) {
 	cacheKey := fmt.Sprintf("son_stats:%s:%s", userID, timeframe)
 
@@ -152,8 +362,8 @@ func (l *SonExecutionLogger) GetSonStats(ctx context.Context, userID string, tim
 	query := `
         SELECT s.id, s.name, 
                COUNT(*) as executions,
-               SUM(CASE WHEN sel.execution_status = 'success' THEN 1 ELSE 0 END) as success,
-               SUM(CASE WHEN sel.execution_status = 'failure' THEN 1 ELSE 0 END) as failure
+               SUM(CASE WHEN sel.execution_status = 'succeeded' THEN 1 ELSE 0 END) as success,
+               SUM(CASE WHEN sel.execution_status IN ('failed', 'partially_failed') THEN 1 ELSE 0 END) as failure
         FROM sons s
         LEFT JOIN son_execution_logs sel ON s.id = sel.son_id
         WHERE s.user_id = ? AND sel.executed_at >= ?
@@ -5,7 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"html/template"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -17,16 +18,44 @@ const (
 	TypeSendTransactionalEmail = "send_transactional_email"
 	TypeManageSubscriber       = "manage_subscriber"
 	TypeCreateCampaign         = "create_campaign"
+	TypeRemoveFromLists        = "remove_from_lists"
+	TypeUnsubscribeSubscriber  = "unsubscribe_subscriber"
+	TypeBlocklistSubscriber    = "blocklist_subscriber"
+	TypeDeleteSubscriber       = "delete_subscriber"
 )
 
+// actionQueue is the queue action tasks run in
+const actionQueue = "default"
+
+// actionTaskTypes maps each action type to the task that performs it
+var actionTaskTypes = map[models.ActionType]string{
+	models.ActionSendTransactionalEmail: TypeSendTransactionalEmail,
+	models.ActionManageSubscriber:       TypeManageSubscriber,
+	models.ActionCreateCampaign:         TypeCreateCampaign,
+	models.ActionRemoveFromLists:        TypeRemoveFromLists,
+	models.ActionUnsubscribeSubscriber:  TypeUnsubscribeSubscriber,
+	models.ActionBlocklistSubscriber:    TypeBlocklistSubscriber,
+	models.ActionDeleteSubscriber:       TypeDeleteSubscriber,
+	models.ActionHTTPRequest:            TypeHTTPRequest,
+}
+
 type SonExecutor struct {
 	listmonkClient  *ListmonkClient
 	asyncClient     *asynq.Client
 	asyncServer     *asynq.Server
 	executionLogger *SonExecutionLogger
+	sonStorage      *SonStorage
+	webhookLogger   *WebhookLogger
+	listMappings    *ListMappingService
+	approvals       *CampaignApprovalService
+	postCampaigns   *PostCampaignService
+	campaignStats   *CampaignStatsService
+	idempotency     idempotencyStore
+	scheduler       *asynq.Scheduler
+	inspector       *asynq.Inspector
 }
 
-func NewSonExecutor(listmonkClient *ListmonkClient, redisAddr string, executionLogger *SonExecutionLogger) (*SonExecutor, error) {
+func NewSonExecutor(listmonkClient *ListmonkClient, redisAddr string, executionLogger *SonExecutionLogger, sonStorage *SonStorage, webhookLogger *WebhookLogger, listMappings *ListMappingService, approvals *CampaignApprovalService, postCampaigns *PostCampaignService, campaignStats *CampaignStatsService, idempotency *IdempotencyStore) (*SonExecutor, error) {
 	asyncClient := asynq.NewClient(asynq.RedisClientOpt{Addr: redisAddr})
 	asyncServer := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: redisAddr},
@@ -37,221 +66,352 @@ func NewSonExecutor(listmonkClient *ListmonkClient, redisAddr string, executionL
 				"default":  3,
 				"low":      1,
 			},
+			RetryDelayFunc: retryDelay,
 		},
 	)
+	scheduler := asynq.NewScheduler(asynq.RedisClientOpt{Addr: redisAddr}, nil)
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: redisAddr})
 
 	return &SonExecutor{
 		listmonkClient:  listmonkClient,
 		asyncClient:     asyncClient,
 		asyncServer:     asyncServer,
 		executionLogger: executionLogger,
+		sonStorage:      sonStorage,
+		webhookLogger:   webhookLogger,
+		listMappings:    listMappings,
+		approvals:       approvals,
+		postCampaigns:   postCampaigns,
+		campaignStats:   campaignStats,
+		idempotency:     idempotency,
+		scheduler:       scheduler,
+		inspector:       inspector,
 	}, nil
 }
 
 func (e *SonExecutor) Start() error {
 	mux := asynq.NewServeMux()
+	mux.Use(e.trackSteps, e.checkExitConditions)
+	mux.HandleFunc(TypeProcessWebhook, e.handleProcessWebhook)
 	mux.HandleFunc(TypeSendTransactionalEmail, e.handleSendTransactionalEmail)
 	mux.HandleFunc(TypeManageSubscriber, e.handleManageSubscriber)
 	mux.HandleFunc(TypeCreateCampaign, e.handleCreateCampaign)
-
+	mux.HandleFunc(TypeRemoveFromLists, e.handleRemoveFromLists)
+	mux.HandleFunc(TypeUnsubscribeSubscriber, e.handleUnsubscribeSubscriber)
+	mux.HandleFunc(TypeBlocklistSubscriber, e.handleBlocklistSubscriber)
+	mux.HandleFunc(TypeDeleteSubscriber, e.handleDeleteSubscriber)
+	mux.HandleFunc(TypeHTTPRequest, e.handleHTTPRequest)
+	mux.HandleFunc(TypeSyncMemberLists, e.handleSyncMemberLists)
+	mux.HandleFunc(TypeExpireCampaignApproval, e.handleExpireCampaignApproval)
+	mux.HandleFunc(TypeSyncPostCampaigns, e.handleSyncPostCampaigns)
+	mux.HandleFunc(TypeSyncCampaignStats, e.handleSyncCampaignStats)
+	mux.HandleFunc(TypeSweepOrphanedCampaigns, e.handleSweepOrphanedCampaigns)
+
+	if err := e.scheduler.Start(); err != nil {
+		return err
+	}
 	return e.asyncServer.Start(mux)
 }
 
 func (e *SonExecutor) Stop() {
+	e.scheduler.Shutdown()
 	e.asyncServer.Shutdown()
 	e.asyncClient.Close()
+	e.inspector.Close()
 }
 
 func (e *SonExecutor) ExecuteSon(son models.Son, data map[string]interface{}, webhookLogID string) {
-	executionID, err := e.executionLogger.LogSonExecution(son.ID, webhookLogID, "success", "")
+	memberEmail, _ := getSubscriberEmail(data)
+	executionID, err := e.executionLogger.LogSonExecution(son.ID, webhookLogID, memberEmail)
 	if err != nil {
 		utils.ErrorLogger.Errorf("Failed to log son execution: %v", err)
 		return
 	}
 
-	for _, action := range son.Actions {
+	sonDelay, err := son.GetParsedDelay()
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to parse delay: %v", err)
+		sonDelay = 0
+	}
+
+	now := time.Now()
+	for i, action := range son.Actions {
 		payload, err := json.Marshal(map[string]interface{}{
 			"action":       action,
+			"action_index": i,
 			"data":         data,
 			"execution_id": executionID,
+			"son_id":       son.ID,
+			"son_name":     son.Name,
+			"trigger":      son.Trigger,
+			"user_id":      son.UserID,
 		})
 		if err != nil {
 			utils.ErrorLogger.Errorf("Failed to marshal action payload: %v", err)
-			e.executionLogger.LogActionExecution(executionID, string(action.Type), "failure", err.Error())
+			e.failStep(executionID, i, action.Type, now, err.Error())
 			continue
 		}
 
-		var task *asynq.Task
-		switch action.Type {
-		case models.ActionSendTransactionalEmail:
-			task = asynq.NewTask(TypeSendTransactionalEmail, payload)
-		case models.ActionManageSubscriber:
-			task = asynq.NewTask(TypeManageSubscriber, payload)
-		case models.ActionCreateCampaign:
-			task = asynq.NewTask(TypeCreateCampaign, payload)
-		default:
+		taskType, ok := actionTaskTypes[action.Type]
+		if !ok {
 			utils.ErrorLogger.Errorf("Unknown action type: %s", action.Type)
-			e.executionLogger.LogActionExecution(executionID, string(action.Type), "failure", "Unknown action type")
+			e.failStep(executionID, i, action.Type, now, "Unknown action type")
 			continue
 		}
+		task := asynq.NewTask(taskType, payload)
 
-		delay, err := son.GetParsedDelay()
+		// Each action runs at its own offset, anchor and send window
+		runAt, err := action.Schedule(now, sonDelay, data)
 		if err != nil {
-			utils.ErrorLogger.Errorf("Failed to parse delay: %v", err)
-			delay = 0
+			utils.ErrorLogger.Errorf("Failed to schedule action %d: %v", i, err)
+			e.failStep(executionID, i, action.Type, now, err.Error())
+			continue
 		}
 
-		info, err := e.asyncClient.Enqueue(task, asynq.ProcessIn(delay), asynq.MaxRetry(3), asynq.Queue("default"))
+		taskID := utils.GenerateUUID()
+		opts := []asynq.Option{asynq.TaskID(taskID), asynq.ProcessAt(runAt), asynq.MaxRetry(action.MaxRetries()), asynq.Queue(actionQueue)}
+		if timeout := action.Timeout(); timeout > 0 {
+			opts = append(opts, asynq.Timeout(timeout))
+		}
+
+		e.recordStep(executionID, i, action.Type, taskID, models.StateQueued, runAt, "")
+		info, err := e.asyncClient.Enqueue(task, opts...)
 		if err != nil {
 			utils.ErrorLogger.Errorf("Failed to enqueue task: %v", err)
-			e.executionLogger.LogActionExecution(executionID, string(action.Type), "failure", err.Error())
-		} else {
-			utils.InfoLogger.Infof("Enqueued task: id=%s queue=%s", info.ID, info.Queue)
-			e.executionLogger.LogActionExecution(executionID, string(action.Type), "queued", "")
+			e.transitionStep(executionID, i, models.StateFailed, err.Error())
+			e.executionLogger.LogActionExecution(executionID, string(action.Type), models.StateFailed, err.Error())
+			continue
 		}
-	}
-}
 
-func (e *SonExecutor) handleSendTransactionalEmail(ctx context.Context, t *asynq.Task) error {
-	var payload map[string]interface{}
-	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %v", err)
+		utils.InfoLogger.Infof("Enqueued task: id=%s queue=%s at=%s", info.ID, info.Queue, runAt.Format(time.RFC3339))
+		e.executionLogger.LogActionExecution(executionID, string(action.Type), models.StateQueued, "")
 	}
 
-	executionID, ok := payload["execution_id"].(string)
-	if !ok {
-		return fmt.Errorf("invalid execution_id in payload")
+	// The execution leaves pending once all its actions have a step
+	if err := e.executionLogger.RefreshExecutionState(executionID); err != nil {
+		utils.ErrorLogger.Errorf("Failed to update state of execution %s: %v", executionID, err)
 	}
+}
 
-	action, ok := payload["action"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid action in payload")
+// SkipSon records that a Son matched the trigger but was not executed.
+func (e *SonExecutor) SkipSon(son models.Son, webhookLogID string, reason string) {
+	if _, err := e.executionLogger.LogSkippedSonExecution(son.ID, webhookLogID, reason); err != nil {
+		utils.ErrorLogger.Errorf("Failed to log skipped son execution: %v", err)
 	}
+}
 
-	params, ok := action["parameters"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid parameters in action")
-	}
+func (e *SonExecutor) handleSendTransactionalEmail(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionSendTransactionalEmail, e.sendTransactionalEmail)
+}
 
-	data, ok := payload["data"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid data in payload")
+func (e *SonExecutor) handleManageSubscriber(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionManageSubscriber, e.manageSubscriber)
+}
+
+// actionFunc performs an action given its parameters and the webhook data
+type actionFunc func(params map[string]interface{}, data map[string]interface{}) error
+
+// runAction decodes an action task, runs it and logs the outcome
+func (e *SonExecutor) runAction(t *asynq.Task, actionType models.ActionType, run actionFunc) error {
+	executionID, params, data, err := parseActionPayload(t)
+	if err != nil {
+		return err
 	}
 
-	err := e.sendTransactionalEmail(params, data)
+	// An email cannot be unsent, so one that may have gone out is not sent again
+	perform := e.once
+	if actionType == models.ActionSendTransactionalEmail {
+		perform = e.atMostOnce
+	}
+	_, err = perform(t, idempotencyActionCompleted, func() (string, error) {
+		return "", run(params, data)
+	})
 	if err != nil {
-		e.executionLogger.LogActionExecution(executionID, "send_transactional_email", "failure", err.Error())
+		e.executionLogger.LogActionExecution(executionID, string(actionType), models.StateFailed, err.Error())
 		return err
 	}
 
-	e.executionLogger.LogActionExecution(executionID, "send_transactional_email", "success", "")
+	e.executionLogger.LogActionExecution(executionID, string(actionType), models.StateSucceeded, "")
 	return nil
 }
 
-func (e *SonExecutor) handleManageSubscriber(ctx context.Context, t *asynq.Task) error {
+func parseActionPayload(t *asynq.Task) (string, map[string]interface{}, map[string]interface{}, error) {
 	var payload map[string]interface{}
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %v", err)
+		return "", nil, nil, fmt.Errorf("failed to unmarshal payload: %v", err)
 	}
 
 	executionID, ok := payload["execution_id"].(string)
 	if !ok {
-		return fmt.Errorf("invalid execution_id in payload")
+		return "", nil, nil, fmt.Errorf("invalid execution_id in payload")
 	}
 
 	action, ok := payload["action"].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid action in payload")
+		return "", nil, nil, fmt.Errorf("invalid action in payload")
 	}
 
 	params, ok := action["parameters"].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid parameters in action")
+		return "", nil, nil, fmt.Errorf("invalid parameters in action")
 	}
 
 	data, ok := payload["data"].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid data in payload")
+		return "", nil, nil, fmt.Errorf("invalid data in payload")
 	}
 
-	err := e.manageSubscriber(params, data)
-	if err != nil {
-		e.executionLogger.LogActionExecution(executionID, "manage_subscriber", "failure", err.Error())
-		return err
-	}
-
-	e.executionLogger.LogActionExecution(executionID, "manage_subscriber", "success", "")
-	return nil
+	return executionID, params, data, nil
 }
 
 func (e *SonExecutor) handleCreateCampaign(ctx context.Context, t *asynq.Task) error {
-	var payload map[string]interface{}
-	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	executionID, params, data, err := parseActionPayload(t)
+	if err != nil {
+		return err
 	}
 
-	executionID, ok := payload["execution_id"].(string)
-	if !ok {
-		return fmt.Errorf("invalid execution_id in payload")
+	owner, err := parseActionOwner(t)
+	if err != nil {
+		return err
 	}
 
-	action, ok := payload["action"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid action in payload")
+	templateContext, err := models.NewTemplateContext(owner.templateSon(), data)
+	if err != nil {
+		e.executionLogger.LogActionExecution(executionID, "create_campaign", models.StateFailed, err.Error())
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
 	}
 
-	params, ok := action["parameters"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid parameters in action")
+	subject, parsedBody, err := models.RenderCampaign(params, templateContext)
+	if err != nil {
+		e.executionLogger.LogActionExecution(executionID, "create_campaign", models.StateFailed, fmt.Sprintf("Failed to parse template: %v", err))
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
 	}
 
-	data, ok := payload["data"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid data in payload")
+	// A retry resumes with the campaign an earlier attempt created, rather
+	// than taking it for the existing campaign of the post
+	resuming, err := e.completed(t, idempotencyCampaignCreated)
+	if err != nil {
+		return err
 	}
 
-	// Parse the template
-	body, ok := params["body"].(string)
-	if !ok {
-		return fmt.Errorf("invalid body in parameters")
+	// A post gets a single campaign per action: edits before the send time
+	// update it and a campaign that already went out is never sent again.
+	postID := getPostID(data)
+	if postID != "" && !resuming {
+		campaignID, err := e.updatePostCampaign(owner, postID, subject, parsedBody)
+		if err != nil {
+			e.executionLogger.LogActionExecution(executionID, "create_campaign", models.StateFailed, err.Error())
+			return err
+		}
+		if campaignID != 0 {
+			e.executionLogger.LogCampaignAction(executionID, "create_campaign", campaignID)
+			return nil
+		}
 	}
 
-	postData := map[string]interface{}{
-		"Title":         data["post"].(map[string]interface{})["current"].(map[string]interface{})["title"],
-		"FeatureImage":  data["post"].(map[string]interface{})["current"].(map[string]interface{})["feature_image"],
-		"Slug":          data["post"].(map[string]interface{})["current"].(map[string]interface{})["slug"],
-		"CustomExcerpt": data["post"].(map[string]interface{})["current"].(map[string]interface{})["custom_excerpt"],
-		"Html":          template.HTML(data["post"].(map[string]interface{})["current"].(map[string]interface{})["html"].(string)),
-		"PlainText":     data["post"].(map[string]interface{})["current"].(map[string]interface{})["plaintext"],
-		"PublishedAt":   data["post"].(map[string]interface{})["current"].(map[string]interface{})["published_at"],
-	}
+	// Update the params with the parsed subject and body
+	params["subject"] = subject
+	params["body"] = parsedBody
 
-	parsedBody, err := utils.ParseTemplate(body, postData)
+	result, err := e.once(t, idempotencyCampaignCreated, func() (string, error) {
+		campaignID, err := e.createCampaign(params, data)
+		return strconv.Itoa(campaignID), err
+	})
 	if err != nil {
-		e.executionLogger.LogActionExecution(executionID, "create_campaign", "failure", fmt.Sprintf("Failed to parse template: %v", err))
+		e.executionLogger.LogActionExecution(executionID, "create_campaign", models.StateFailed, err.Error())
 		return err
 	}
-
-	// Update the params with the parsed body
-	params["body"] = parsedBody
-
-	campaignID, err := e.createCampaign(params, data)
+	campaignID, err := strconv.Atoi(result)
 	if err != nil {
-		e.executionLogger.LogActionExecution(executionID, "create_campaign", "failure", err.Error())
+		return fmt.Errorf("invalid campaign ID %q recorded for the step: %v: %w", result, err, asynq.SkipRetry)
+	}
+
+	// From here on the campaign exists, so an action failing for good deletes
+	// it rather than leaving an unscheduled draft behind
+	fail := func(actionType string, err error) error {
+		e.executionLogger.LogActionExecution(executionID, actionType, models.StateFailed, err.Error())
+		if isFinalAttempt(ctx, err) {
+			e.compensateCampaign(t, executionID, campaignID)
+		}
 		return err
 	}
 
+	if postID != "" {
+		// The campaign exists at this point, so a lost mapping must not fail
+		// the task and create it a second time on retry.
+		if err := e.postCampaigns.Save(&models.PostCampaign{
+			UserID:      owner.UserID,
+			SonID:       owner.SonID,
+			ActionIndex: owner.ActionIndex,
+			PostID:      postID,
+			CampaignID:  campaignID,
+		}); err != nil {
+			utils.ErrorLogger.Errorf("Failed to map post %s to campaign %d: %v", postID, campaignID, err)
+		}
+	}
+	e.trackCampaignStats(owner, campaignID, params, data)
+
+	// Campaigns that need approval stay as drafts until someone approves them
+	if approvalRequired, _ := params["approval_required"].(bool); approvalRequired {
+		_, err := e.once(t, idempotencyApprovalRequested, func() (string, error) {
+			return "", e.requestCampaignApproval(owner, executionID, campaignID, params)
+		})
+		if err != nil {
+			return fail("create_campaign", err)
+		}
+		e.executionLogger.LogCampaignAction(executionID, "create_campaign", campaignID)
+		return nil
+	}
+
 	// Update the campaign status to 'scheduled'
-	err = e.listmonkClient.UpdateCampaignStatus(campaignID, "scheduled")
+	_, err = e.once(t, idempotencyCampaignScheduled, func() (string, error) {
+		return "", e.listmonkClient.UpdateCampaignStatus(campaignID, "scheduled")
+	})
 	if err != nil {
-		e.executionLogger.LogActionExecution(executionID, "update_campaign_status", "failure", err.Error())
-		return err
+		return fail("update_campaign_status", err)
 	}
 
-	e.executionLogger.LogActionExecution(executionID, "create_campaign", "success", "")
+	e.executionLogger.LogCampaignAction(executionID, "create_campaign", campaignID)
 	return nil
 }
 
+// actionOwner identifies the Son action a task was queued for
+type actionOwner struct {
+	SonID       string             `json:"son_id"`
+	SonName     string             `json:"son_name"`
+	Trigger     models.TriggerType `json:"trigger"`
+	UserID      string             `json:"user_id"`
+	ActionIndex int                `json:"action_index"`
+}
+
+func (o actionOwner) templateSon() models.TemplateSon {
+	return models.TemplateSon{ID: o.SonID, Name: o.SonName, Trigger: o.Trigger}
+}
+
+func parseActionOwner(t *asynq.Task) (actionOwner, error) {
+	var owner actionOwner
+	if err := json.Unmarshal(t.Payload(), &owner); err != nil || owner.UserID == "" {
+		return owner, fmt.Errorf("invalid user_id in payload")
+	}
+	return owner, nil
+}
+
+func (e *SonExecutor) handleRemoveFromLists(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionRemoveFromLists, e.removeFromLists)
+}
+
+func (e *SonExecutor) handleUnsubscribeSubscriber(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionUnsubscribeSubscriber, e.unsubscribeSubscriber)
+}
+
+func (e *SonExecutor) handleBlocklistSubscriber(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionBlocklistSubscriber, e.blocklistSubscriber)
+}
+
+func (e *SonExecutor) handleDeleteSubscriber(ctx context.Context, t *asynq.Task) error {
+	return e.runAction(t, models.ActionDeleteSubscriber, e.deleteSubscriber)
+}
+
 func (e *SonExecutor) sendTransactionalEmail(params map[string]interface{}, data map[string]interface{}) error {
 	templateID, ok := params["template_id"].(float64)
 	if !ok {
@@ -298,36 +458,114 @@ func (e *SonExecutor) manageSubscriber(params map[string]interface{}, data map[s
 
 	status := "enabled" // params["status"].(string)
 
-	lists := []int{}
-	if listsParam, ok := params["lists"]; ok && listsParam != nil {
-		if listSlice, ok := listsParam.([]interface{}); ok {
-			for _, listID := range listSlice {
-				if id, ok := listID.(float64); ok {
-					lists = append(lists, int(id))
-				}
-			}
-		}
+	lists := getListIDs(params)
+
+	mappings, err := models.ParseAttributeMappings(params["attribute_mappings"])
+	if err != nil {
+		return err
+	}
+	if mappings == nil {
+		mappings = models.DefaultAttributeMappings
 	}
 
-	var geoLocation map[string]interface{}
-	if geoStr, ok := current["geolocation"].(string); ok {
-		err := json.Unmarshal([]byte(geoStr), &geoLocation)
-		if err != nil {
-			utils.ErrorLogger.Errorf("Error parsing geolocation data: %v", err)
+	attributes, mappingErrs := models.MapAttributes(mappings, current)
+	for _, err := range mappingErrs {
+		utils.ErrorLogger.Errorf("Error mapping attributes for %s: %v", email, err)
+	}
+
+	listMode := ListModeMerge
+	if mode, ok := params["list_mode"].(string); ok && mode != "" {
+		listMode = ListMode(mode)
+	}
+
+	// When the member changed their email, the Listmonk subscriber still has
+	// the old address, so look that one up first.
+	lookupEmails := []string{email}
+	if previous, ok := member["previous"].(map[string]interface{}); ok {
+		if previousEmail, ok := previous["email"].(string); ok && previousEmail != "" && !strings.EqualFold(previousEmail, email) {
+			lookupEmails = []string{previousEmail, email}
 		}
 	}
 
-	attributes := make(map[string]interface{})
-	if geoLocation != nil {
-		attributes["city"] = geoLocation["city"]
-		attributes["country"] = geoLocation["country"]
-		attributes["latitude"] = geoLocation["latitude"]
-		attributes["longitude"] = geoLocation["longitude"]
-		attributes["timezone"] = geoLocation["timezone"]
+	utils.InfoLogger.Infof("Managing subscriber %s with status %s, lists %v (%s), and attributes %v", email, status, lists, listMode, attributes)
+	return e.listmonkClient.ManageSubscriber(SubscriberData{
+		Email:      email,
+		Name:       name,
+		Status:     status,
+		Lists:      lists,
+		Attributes: attributes,
+	}, lookupEmails, listMode)
+}
+
+func (e *SonExecutor) removeFromLists(params map[string]interface{}, data map[string]interface{}) error {
+	lists := getListIDs(params)
+	if len(lists) == 0 {
+		return fmt.Errorf("invalid or missing lists")
+	}
+
+	subscriber, err := e.findSubscriber(data)
+	if err != nil || subscriber == nil {
+		return err
+	}
+
+	utils.InfoLogger.Infof("Removing subscriber %s from lists %v", subscriber.Email, lists)
+	return e.listmonkClient.RemoveSubscriberFromLists(subscriber.ID, lists)
+}
+
+func (e *SonExecutor) unsubscribeSubscriber(params map[string]interface{}, data map[string]interface{}) error {
+	subscriber, err := e.findSubscriber(data)
+	if err != nil || subscriber == nil {
+		return err
+	}
+
+	lists := make([]int, 0, len(subscriber.Lists))
+	for _, list := range subscriber.Lists {
+		lists = append(lists, list.ID)
+	}
+	if len(lists) == 0 {
+		utils.InfoLogger.Infof("Subscriber %s is not on any list", subscriber.Email)
+		return nil
+	}
+
+	utils.InfoLogger.Infof("Unsubscribing subscriber %s from lists %v", subscriber.Email, lists)
+	return e.listmonkClient.UnsubscribeSubscriber(subscriber.ID, lists)
+}
+
+func (e *SonExecutor) blocklistSubscriber(params map[string]interface{}, data map[string]interface{}) error {
+	subscriber, err := e.findSubscriber(data)
+	if err != nil || subscriber == nil {
+		return err
+	}
+
+	utils.InfoLogger.Infof("Blocklisting subscriber %s", subscriber.Email)
+	return e.listmonkClient.BlocklistSubscriber(subscriber.ID)
+}
+
+func (e *SonExecutor) deleteSubscriber(params map[string]interface{}, data map[string]interface{}) error {
+	subscriber, err := e.findSubscriber(data)
+	if err != nil || subscriber == nil {
+		return err
 	}
 
-	utils.InfoLogger.Infof("Managing subscriber %s with status %s, lists %v, and attributes %v", email, status, lists, attributes)
-	return e.listmonkClient.ManageSubscriber(email, name, status, lists, attributes)
+	utils.InfoLogger.Infof("Deleting subscriber %s", subscriber.Email)
+	return e.listmonkClient.DeleteSubscriber(subscriber.ID)
+}
+
+// findSubscriber looks up the Listmonk subscriber for the member in the
+// payload. A missing subscriber returns nil without an error, since there is
+// nothing left to remove.
+func (e *SonExecutor) findSubscriber(data map[string]interface{}) (*ListmonkSubscriber, error) {
+	email, err := getSubscriberEmail(data)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber, err := e.listmonkClient.GetSubscriberByEmail(email)
+	if err == ErrSubscriberNotFound {
+		utils.InfoLogger.Infof("Subscriber %s not found in Listmonk, nothing to do", email)
+		return nil, nil
+	}
+	return subscriber, err
 }
 
 func (e *SonExecutor) createCampaign(params map[string]interface{}, data map[string]interface{}) (int, error) {
@@ -394,23 +632,35 @@ func (e *SonExecutor) createCampaign(params map[string]interface{}, data map[str
 	return e.listmonkClient.CreateCampaign(uniqueName, subject, listIDs, int(templateID), sendAt, body, contentType)
 }
 
+func getListIDs(params map[string]interface{}) []int {
+	lists := []int{}
+	if listSlice, ok := params["lists"].([]interface{}); ok {
+		for _, listID := range listSlice {
+			if id, ok := listID.(float64); ok {
+				lists = append(lists, int(id))
+			}
+		}
+	}
+	return lists
+}
+
+// getSubscriberEmail returns the member email, falling back to the previous
+// version for member.deleted payloads whose current version is empty.
 func getSubscriberEmail(data map[string]interface{}) (string, error) {
 	member, ok := data["member"].(map[string]interface{})
 	if !ok {
 		return "", fmt.Errorf("invalid member data")
 	}
 
-	current, ok := member["current"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid current member data")
-	}
-
-	email, ok := current["email"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid or missing email")
+	for _, version := range []string{"current", "previous"} {
+		if m, ok := member[version].(map[string]interface{}); ok {
+			if email, ok := m["email"].(string); ok && email != "" {
+				return email, nil
+			}
+		}
 	}
 
-	return email, nil
+	return "", fmt.Errorf("invalid or missing email")
 }
 
 func getHeaders(params map[string]interface{}) ([]map[string]string, error) {
@@ -36,9 +36,27 @@ func (s *SonStorage) Create(son *models.Son) error {
 		return err
 	}
 
+	fieldChangesJSON, err := json.Marshal(son.FieldChanges)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to marshal field changes: %v", err)
+		return err
+	}
+
+	conditionsJSON, err := json.Marshal(son.Conditions)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to marshal conditions: %v", err)
+		return err
+	}
+
+	exitConditionsJSON, err := json.Marshal(son.ExitConditions)
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to marshal exit conditions: %v", err)
+		return err
+	}
+
 	_, err = s.db.Exec(
-		"INSERT INTO sons (id, user_id, name, trigger_event, delay, actions, enabled, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())",
-		son.ID, son.UserID, son.Name, son.Trigger, son.Delay, actionsJSON, son.Enabled,
+		"INSERT INTO sons (id, user_id, name, trigger_event, delay, actions, field_changes, conditions, exit_conditions, enabled, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())",
+		son.ID, son.UserID, son.Name, son.Trigger, son.Delay, actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON, son.Enabled,
 	)
 	if err != nil {
 		utils.ErrorLogger.Errorf("Failed to create Son: %v", err)
@@ -55,12 +73,12 @@ func (s *SonStorage) Create(son *models.Son) error {
 
 func (s *SonStorage) Get(id string) (models.Son, error) {
 	var son models.Son
-	var actionsJSON []byte
+	var actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON []byte
 
 	err := s.db.QueryRow(
-		"SELECT id, user_id, name, trigger_event, delay, actions, enabled, created_at, updated_at FROM sons WHERE id = ?",
+		"SELECT id, user_id, name, trigger_event, delay, actions, field_changes, conditions, exit_conditions, enabled, created_at, updated_at FROM sons WHERE id = ?",
 		id,
-	).Scan(&son.ID, &son.UserID, &son.Name, &son.Trigger, &son.Delay, &actionsJSON, &son.Enabled, &son.CreatedAt, &son.UpdatedAt)
+	).Scan(&son.ID, &son.UserID, &son.Name, &son.Trigger, &son.Delay, &actionsJSON, &fieldChangesJSON, &conditionsJSON, &exitConditionsJSON, &son.Enabled, &son.CreatedAt, &son.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -71,9 +89,9 @@ func (s *SonStorage) Get(id string) (models.Son, error) {
 		return models.Son{}, err
 	}
 
-	err = json.Unmarshal(actionsJSON, &son.Actions)
+	err = unmarshalSonJSON(&son, actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON)
 	if err != nil {
-		utils.ErrorLogger.Errorf("Failed to unmarshal actions: %v", err)
+		utils.ErrorLogger.Errorf("Failed to unmarshal Son: %v", err)
 		return models.Son{}, err
 	}
 
@@ -87,9 +105,24 @@ func (s *SonStorage) Update(son models.Son) error {
 		return err
 	}
 
+	fieldChangesJSON, err := json.Marshal(son.FieldChanges)
+	if err != nil {
+		return err
+	}
+
+	conditionsJSON, err := json.Marshal(son.Conditions)
+	if err != nil {
+		return err
+	}
+
+	exitConditionsJSON, err := json.Marshal(son.ExitConditions)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.Exec(
-		"UPDATE sons SET name = ?, trigger_event = ?, delay = ?, actions = ?, enabled = ?, updated_at = NOW() WHERE id = ? AND user_id = ?",
-		son.Name, son.Trigger, son.Delay, actionsJSON, son.Enabled, son.ID, son.UserID,
+		"UPDATE sons SET name = ?, trigger_event = ?, delay = ?, actions = ?, field_changes = ?, conditions = ?, exit_conditions = ?, enabled = ?, updated_at = NOW() WHERE id = ? AND user_id = ?",
+		son.Name, son.Trigger, son.Delay, actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON, son.Enabled, son.ID, son.UserID,
 	)
 	if err != nil {
 		utils.ErrorLogger.Errorf("Failed to update Son: %v", err)
@@ -140,7 +173,7 @@ func (s *SonStorage) Delete(id string, userID string) error {
 }
 
 func (s *SonStorage) List(userID string) ([]models.Son, error) {
-	rows, err := s.db.Query("SELECT id, user_id, name, trigger_event, delay, actions, enabled, created_at, updated_at FROM sons WHERE user_id = ?", userID)
+	rows, err := s.db.Query("SELECT id, user_id, name, trigger_event, delay, actions, field_changes, conditions, exit_conditions, enabled, created_at, updated_at FROM sons WHERE user_id = ?", userID)
 	if err != nil {
 		utils.ErrorLogger.Errorf("Failed to list Sons: %v", err)
 		return nil, err
@@ -150,17 +183,17 @@ func (s *SonStorage) List(userID string) ([]models.Son, error) {
 	var sons []models.Son
 	for rows.Next() {
 		var son models.Son
-		var actionsJSON []byte
+		var actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON []byte
 
-		err := rows.Scan(&son.ID, &son.UserID, &son.Name, &son.Trigger, &son.Delay, &actionsJSON, &son.Enabled, &son.CreatedAt, &son.UpdatedAt)
+		err := rows.Scan(&son.ID, &son.UserID, &son.Name, &son.Trigger, &son.Delay, &actionsJSON, &fieldChangesJSON, &conditionsJSON, &exitConditionsJSON, &son.Enabled, &son.CreatedAt, &son.UpdatedAt)
 		if err != nil {
 			utils.ErrorLogger.Errorf("Failed to scan Son: %v", err)
 			continue
 		}
 
-		err = json.Unmarshal(actionsJSON, &son.Actions)
+		err = unmarshalSonJSON(&son, actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON)
 		if err != nil {
-			utils.ErrorLogger.Errorf("Failed to unmarshal actions: %v", err)
+			utils.ErrorLogger.Errorf("Failed to unmarshal Son: %v", err)
 			continue
 		}
 
@@ -174,3 +207,30 @@ func (s *SonStorage) List(userID string) ([]models.Son, error) {
 	utils.InfoLogger.Infof("Retrieved list of %d Sons for user %s", len(sons), userID)
 	return sons, nil
 }
+
+// unmarshalSonJSON decodes the JSON columns of a sons row
+func unmarshalSonJSON(son *models.Son, actionsJSON, fieldChangesJSON, conditionsJSON, exitConditionsJSON []byte) error {
+	if err := json.Unmarshal(actionsJSON, &son.Actions); err != nil {
+		return fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+
+	if len(fieldChangesJSON) > 0 {
+		if err := json.Unmarshal(fieldChangesJSON, &son.FieldChanges); err != nil {
+			return fmt.Errorf("failed to unmarshal field changes: %w", err)
+		}
+	}
+
+	if len(conditionsJSON) > 0 {
+		if err := json.Unmarshal(conditionsJSON, &son.Conditions); err != nil {
+			return fmt.Errorf("failed to unmarshal conditions: %w", err)
+		}
+	}
+
+	if len(exitConditionsJSON) > 0 {
+		if err := json.Unmarshal(exitConditionsJSON, &son.ExitConditions); err != nil {
+			return fmt.Errorf("failed to unmarshal exit conditions: %w", err)
+		}
+	}
+
+	return nil
+}
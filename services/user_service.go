@@ -75,7 +75,7 @@ func (s *UserService) CreateUser(email string) (*models.User, error) {
 	}
 
 	// Create default webhook in a separate operation
-	_, err = s.webhookService.CreateWebhook(id)
+	_, err = s.webhookService.CreateWebhook(id, "Default")
 	if err != nil {
 		utils.ErrorLogger.Printf("Failed to create default webhook: %v", err)
 		// Note: We don't return here because the user has been created successfully
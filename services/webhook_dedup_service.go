@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/troneras/ghost-listmonk-connector/models"
+)
+
+// WebhookDeduplicator remembers which deliveries have already been accepted,
+// so Ghost retries and manual replays do not run the same Sons twice.
+type WebhookDeduplicator struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func NewWebhookDeduplicator(redisAddr string, ttl time.Duration) *WebhookDeduplicator {
+	return &WebhookDeduplicator{
+		redis: redis.NewClient(&redis.Options{Addr: redisAddr}),
+		ttl:   ttl,
+	}
+}
+
+// DedupKey identifies a delivery by endpoint, event, entity id and the
+// entity's updated_at. It returns false when the payload carries no entity
+// to key on (e.g. site.changed), in which case the delivery is not deduplicated.
+func DedupKey(endpoint string, trigger models.TriggerType, data map[string]interface{}) (string, bool) {
+	for _, resource := range []string{"member", "post", "page", "tag"} {
+		entity, ok := data[resource].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Deletions only carry the previous version of the entity
+		version, _ := entity["current"].(map[string]interface{})
+		if len(version) == 0 {
+			version, _ = entity["previous"].(map[string]interface{})
+		}
+
+		id, _ := version["id"].(string)
+		updatedAt, _ := version["updated_at"].(string)
+		if id == "" || updatedAt == "" {
+			return "", false
+		}
+
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", endpoint, trigger, id, updatedAt)))
+		return "webhook_dedup:" + hex.EncodeToString(sum[:]), true
+	}
+	return "", false
+}
+
+// Claim records the key and reports whether it was new. A false result means
+// the delivery has already been accepted within the TTL.
+func (d *WebhookDeduplicator) Claim(ctx context.Context, key string, webhookLogID string) (bool, error) {
+	return d.redis.SetNX(ctx, key, webhookLogID, d.ttl).Result()
+}
+
+// Release forgets a key, used when a claimed delivery could not be queued and
+// Ghost has to be able to retry it.
+func (d *WebhookDeduplicator) Release(ctx context.Context, key string) error {
+	return d.redis.Del(ctx, key).Err()
+}
+
+// OriginalLogID returns the webhook log that first claimed the key
+func (d *WebhookDeduplicator) OriginalLogID(ctx context.Context, key string) string {
+	logID, _ := d.redis.Get(ctx, key).Result()
+	return logID
+}
@@ -10,6 +10,16 @@ import (
 	"github.com/troneras/ghost-listmonk-connector/utils"
 )
 
+// Processing states of a webhook delivery once it has been accepted
+const (
+	WebhookProcessingQueued    = "queued"
+	WebhookProcessingRunning   = "processing"
+	WebhookProcessingRetrying  = "retrying"
+	WebhookProcessingProcessed = "processed"
+	WebhookProcessingFailed    = "failed"
+	WebhookProcessingDuplicate = "duplicate"
+)
+
 type WebhookLogger struct {
 	db *sql.DB
 }
@@ -67,6 +77,51 @@ func (l *WebhookLogger) UpdateWebhookLog(logID string, statusCode int, response
 	return nil
 }
 
+// RejectWebhookLog finalises the log of a delivery that was refused, storing
+// the reason code alongside the response.
+func (l *WebhookLogger) RejectWebhookLog(logID string, statusCode int, reason string, response interface{}, duration time.Duration) error {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.db.Exec(`
+		UPDATE webhook_logs
+		SET status_code = ?, response_body = ?, duration = ?, rejection_reason = ?
+		WHERE id = ?
+	`, statusCode, string(responseJSON), int(duration.Milliseconds()), reason, logID)
+
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to update rejected webhook log: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateProcessingStatus records the progress of the process_webhook task.
+// Each transition to processing counts as an attempt.
+func (l *WebhookLogger) UpdateProcessingStatus(logID string, status string, errorMessage string) error {
+	attempt := 0
+	if status == WebhookProcessingRunning {
+		attempt = 1
+	}
+
+	_, err := l.db.Exec(`
+		UPDATE webhook_logs
+		SET processing_status = ?, processing_error = ?, processing_attempts = processing_attempts + ?,
+			processed_at = CASE WHEN ? IN ('processed', 'failed') THEN CURRENT_TIMESTAMP ELSE processed_at END
+		WHERE id = ?
+	`, status, errorMessage, attempt, status, logID)
+
+	if err != nil {
+		utils.ErrorLogger.Errorf("Failed to update webhook processing status: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func (l *WebhookLogger) GetWebhookLogs(userID string, limit, offset int) ([]WebhookLog, int, error) {
 	// First, get the total count of logs for this user
 	var total int
@@ -78,7 +133,7 @@ func (l *WebhookLogger) GetWebhookLogs(userID string, limit, offset int) ([]Webh
 
 	// Now, get the paginated logs
 	rows, err := l.db.Query(`
-		SELECT id, timestamp, method, path, status_code, duration
+		SELECT id, timestamp, method, path, status_code, duration, COALESCE(rejection_reason, ''), COALESCE(processing_status, '')
 		FROM webhook_logs
 		WHERE user_id = ?
 		ORDER BY timestamp DESC
@@ -93,7 +148,7 @@ func (l *WebhookLogger) GetWebhookLogs(userID string, limit, offset int) ([]Webh
 	var logs []WebhookLog
 	for rows.Next() {
 		var log WebhookLog
-		err := rows.Scan(&log.ID, &log.Timestamp, &log.Method, &log.Path, &log.StatusCode, &log.Duration)
+		err := rows.Scan(&log.ID, &log.Timestamp, &log.Method, &log.Path, &log.StatusCode, &log.Duration, &log.RejectionReason, &log.ProcessingStatus)
 		if err != nil {
 			utils.ErrorLogger.Printf("Failed to scan webhook log: %v", err)
 			return nil, 0, err
@@ -113,10 +168,12 @@ func (l *WebhookLogger) GetWebhookLogs(userID string, limit, offset int) ([]Webh
 func (l *WebhookLogger) GetWebhookLogDetails(id string) (*WebhookLogDetails, error) {
 	var log WebhookLogDetails
 	err := l.db.QueryRow(`
-		SELECT id, user_id, timestamp, method, path, headers, body, status_code, response_body, duration
+		SELECT id, user_id, timestamp, method, path, headers, body, status_code, response_body, duration, COALESCE(rejection_reason, ''),
+			COALESCE(processing_status, ''), COALESCE(processing_error, ''), processing_attempts, processed_at
 		FROM webhook_logs
 		WHERE id = ?
-	`, id).Scan(&log.ID, &log.UserID, &log.Timestamp, &log.Method, &log.Path, &log.Headers, &log.Body, &log.StatusCode, &log.ResponseBody, &log.Duration)
+	`, id).Scan(&log.ID, &log.UserID, &log.Timestamp, &log.Method, &log.Path, &log.Headers, &log.Body, &log.StatusCode, &log.ResponseBody, &log.Duration, &log.RejectionReason,
+		&log.ProcessingStatus, &log.ProcessingError, &log.ProcessingAttempts, &log.ProcessedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -127,10 +184,12 @@ func (l *WebhookLogger) GetWebhookLogDetails(id string) (*WebhookLogDetails, err
 func (l *WebhookLogger) GetWebhookLogForReplay(id string) (*WebhookLogDetails, error) {
 	var log WebhookLogDetails
 	err := l.db.QueryRow(`
-		SELECT id, user_id, timestamp, method, path, headers, body, status_code, response_body, duration
+		SELECT id, user_id, timestamp, method, path, headers, body, status_code, response_body, duration, COALESCE(rejection_reason, ''),
+			COALESCE(processing_status, ''), COALESCE(processing_error, ''), processing_attempts, processed_at
 		FROM webhook_logs
 		WHERE id = ?
-	`, id).Scan(&log.ID, &log.UserID, &log.Timestamp, &log.Method, &log.Path, &log.Headers, &log.Body, &log.StatusCode, &log.ResponseBody, &log.Duration)
+	`, id).Scan(&log.ID, &log.UserID, &log.Timestamp, &log.Method, &log.Path, &log.Headers, &log.Body, &log.StatusCode, &log.ResponseBody, &log.Duration, &log.RejectionReason,
+		&log.ProcessingStatus, &log.ProcessingError, &log.ProcessingAttempts, &log.ProcessedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +205,9 @@ type WebhookLog struct {
 	Path       string    `json:"path"`
 	StatusCode int       `json:"status_code"`
 	Duration   int       `json:"duration"`
+
+	RejectionReason  string `json:"rejection_reason,omitempty"`
+	ProcessingStatus string `json:"processing_status,omitempty"`
 }
 
 type WebhookLogDetails struct {
@@ -153,4 +215,8 @@ type WebhookLogDetails struct {
 	Headers      string `json:"headers"`
 	Body         string `json:"body"`
 	ResponseBody string `json:"response_body"`
+
+	ProcessingError    string     `json:"processing_error,omitempty"`
+	ProcessingAttempts int        `json:"processing_attempts"`
+	ProcessedAt        *time.Time `json:"processed_at,omitempty"`
 }
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+const TypeProcessWebhook = "process_webhook"
+
+// ProcessWebhookPayload is the task payload persisted for every accepted
+// webhook delivery before Ghost gets its response.
+type ProcessWebhookPayload struct {
+	WebhookLogID string                 `json:"webhook_log_id"`
+	UserID       string                 `json:"user_id"`
+	Trigger      models.TriggerType     `json:"trigger"`
+	Data         map[string]interface{} `json:"data"`
+}
+
+// EnqueueWebhook persists a process_webhook task. Once it returns without
+// error the delivery survives a restart and will be retried on failure.
+func (e *SonExecutor) EnqueueWebhook(payload ProcessWebhookPayload) (*asynq.TaskInfo, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	info, err := e.asyncClient.Enqueue(asynq.NewTask(TypeProcessWebhook, data), asynq.MaxRetry(5), asynq.Queue("critical"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue webhook: %w", err)
+	}
+
+	if err := e.webhookLogger.UpdateProcessingStatus(payload.WebhookLogID, WebhookProcessingQueued, ""); err != nil {
+		utils.ErrorLogger.Errorf("Failed to update webhook processing status: %v", err)
+	}
+
+	utils.InfoLogger.Infof("Enqueued webhook %s: task=%s", payload.WebhookLogID, info.ID)
+	return info, nil
+}
+
+func (e *SonExecutor) handleProcessWebhook(ctx context.Context, t *asynq.Task) (err error) {
+	var payload ProcessWebhookPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := e.webhookLogger.UpdateProcessingStatus(payload.WebhookLogID, WebhookProcessingRunning, ""); err != nil {
+		utils.ErrorLogger.Errorf("Failed to update webhook processing status: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while processing webhook: %v", r)
+		}
+
+		status := WebhookProcessingProcessed
+		errorMessage := ""
+		if err != nil {
+			utils.ErrorLogger.Errorf("Failed to process webhook %s: %v", payload.WebhookLogID, err)
+			status = WebhookProcessingRetrying
+			if isFinalAttempt(ctx, err) {
+				status = WebhookProcessingFailed
+			}
+			errorMessage = err.Error()
+		}
+
+		if statusErr := e.webhookLogger.UpdateProcessingStatus(payload.WebhookLogID, status, errorMessage); statusErr != nil {
+			utils.ErrorLogger.Errorf("Failed to update webhook processing status: %v", statusErr)
+		}
+	}()
+
+	return e.processWebhook(payload)
+}
+
+// processWebhook runs every enabled Son subscribed to the trigger
+func (e *SonExecutor) processWebhook(payload ProcessWebhookPayload) error {
+	sons, err := e.sonStorage.List(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list Sons: %w", err)
+	}
+
+	// Queue the list and campaign syncs before running any Son, so a failure
+	// to queue them retries the webhook before anything has been executed.
+	if payload.Trigger == models.TriggerMemberCreated || payload.Trigger == models.TriggerMemberUpdated {
+		if err := e.EnqueueMemberListSync(payload); err != nil {
+			return err
+		}
+	}
+
+	switch payload.Trigger {
+	case models.TriggerPostPublishedEdited, models.TriggerPostEdited, models.TriggerPostUnpublished, models.TriggerPostDeleted:
+		if err := e.EnqueuePostCampaignSync(payload); err != nil {
+			return err
+		}
+	}
+
+	// Member updates can be narrowed down to specific field changes
+	var memberDiff models.MemberDiff
+	if payload.Trigger == models.TriggerMemberUpdated {
+		memberDiff = models.ComputeMemberDiff(payload.Data)
+		utils.InfoLogger.Infof("Member diff: %s", utils.PrettyPrint(memberDiff))
+	}
+
+	// Cancel what earlier executions still have queued for a member who left
+	// a sequence, before running the Sons of this webhook
+	if exits := models.MemberExits(payload.Trigger, memberDiff); len(exits) > 0 {
+		memberEmail, _ := getSubscriberEmail(payload.Data)
+		if err := e.cancelMemberSteps(payload.UserID, memberEmail, exits, sons); err != nil {
+			return err
+		}
+	}
+
+	executedCount := 0
+	skippedCount := 0
+	for _, son := range sons {
+		if son.Trigger != payload.Trigger || !son.Enabled || !son.MatchesMemberDiff(memberDiff) {
+			continue
+		}
+
+		if ok, reason := son.EvaluateConditions(payload.Data); !ok {
+			utils.InfoLogger.Infof("Skipping Son %s: %s", son.ID, reason)
+			e.SkipSon(son, payload.WebhookLogID, reason)
+			skippedCount++
+			continue
+		}
+
+		utils.InfoLogger.Infof("Executing Son %s for trigger %s", son.ID, payload.Trigger)
+		e.ExecuteSon(son, payload.Data, payload.WebhookLogID)
+		executedCount++
+	}
+
+	utils.InfoLogger.Infof("Executed %d Sons and skipped %d for trigger %s", executedCount, skippedCount, payload.Trigger)
+	return nil
+}
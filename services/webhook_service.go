@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/troneras/ghost-listmonk-connector/database"
@@ -9,6 +10,10 @@ import (
 	"github.com/troneras/ghost-listmonk-connector/utils"
 )
 
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const webhookColumns = "id, user_id, label, endpoint, secret, previous_secret, previous_secret_expires_at, created_at, updated_at"
+
 type WebhookService struct {
 	db *sql.DB
 }
@@ -17,23 +22,24 @@ func NewWebhookService() *WebhookService {
 	return &WebhookService{db: database.GetDB()}
 }
 
-func (s *WebhookService) CreateWebhook(userID string) (*models.Webhook, error) {
+func (s *WebhookService) CreateWebhook(userID string, label string) (*models.Webhook, error) {
 	id := utils.GenerateUUID()
 	endpoint := id
 	secret := utils.GenerateSecret()
 	now := time.Now()
 
-	_, err := s.db.Exec("INSERT INTO webhooks (id, user_id, endpoint, secret, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
-		id, userID, endpoint, secret, now, now)
+	_, err := s.db.Exec("INSERT INTO webhooks (id, user_id, label, endpoint, secret, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		id, userID, label, endpoint, secret, now, now)
 	if err != nil {
 		utils.ErrorLogger.Printf("Failed to create webhook for user %s: %v", userID, err)
 		return nil, err
 	}
 
-	utils.InfoLogger.Printf("Created webhook for user %s", userID)
+	utils.InfoLogger.Printf("Created webhook %s for user %s", id, userID)
 	return &models.Webhook{
 		ID:        id,
 		UserID:    userID,
+		Label:     label,
 		Endpoint:  endpoint,
 		Secret:    secret,
 		CreatedAt: now,
@@ -42,7 +48,7 @@ func (s *WebhookService) CreateWebhook(userID string) (*models.Webhook, error) {
 }
 
 func (s *WebhookService) GetWebhooksByUserID(userID string) ([]models.Webhook, error) {
-	rows, err := s.db.Query("SELECT id, user_id, endpoint, secret, created_at, updated_at FROM webhooks WHERE user_id = ?", userID)
+	rows, err := s.db.Query("SELECT "+webhookColumns+" FROM webhooks WHERE user_id = ? ORDER BY created_at ASC", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,23 +56,113 @@ func (s *WebhookService) GetWebhooksByUserID(userID string) ([]models.Webhook, e
 
 	var webhooks []models.Webhook
 	for rows.Next() {
-		var webhook models.Webhook
-		err := rows.Scan(&webhook.ID, &webhook.UserID, &webhook.Endpoint, &webhook.Secret, &webhook.CreatedAt, &webhook.UpdatedAt)
+		webhook, err := scanWebhook(rows)
 		if err != nil {
 			return nil, err
 		}
-		webhooks = append(webhooks, webhook)
+		webhooks = append(webhooks, *webhook)
 	}
 
 	return webhooks, nil
 }
 
 func (s *WebhookService) GetWebhookByEndpoint(endpoint string) (*models.Webhook, error) {
+	return scanWebhook(s.db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE endpoint = ?", endpoint))
+}
+
+func (s *WebhookService) GetWebhook(id string, userID string) (*models.Webhook, error) {
+	webhook, err := scanWebhook(s.db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE id = ? AND user_id = ?", id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+func (s *WebhookService) UpdateWebhookLabel(id string, userID string, label string) (*models.Webhook, error) {
+	result, err := s.db.Exec("UPDATE webhooks SET label = ?, updated_at = ? WHERE id = ? AND user_id = ?", label, time.Now(), id, userID)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to update webhook %s: %v", id, err)
+		return nil, err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rowsAffected == 0 {
+		return nil, ErrWebhookNotFound
+	}
+
+	return s.GetWebhook(id, userID)
+}
+
+func (s *WebhookService) DeleteWebhook(id string, userID string) error {
+	result, err := s.db.Exec("DELETE FROM webhooks WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to delete webhook %s: %v", id, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	utils.InfoLogger.Printf("Deleted webhook %s for user %s", id, userID)
+	return nil
+}
+
+// RotateSecret issues a new secret for a webhook. The current secret is kept
+// as the previous secret and accepted for the given grace period.
+func (s *WebhookService) RotateSecret(id string, userID string, gracePeriod time.Duration) (*models.Webhook, error) {
+	webhook, err := s.GetWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(gracePeriod)
+	newSecret := utils.GenerateSecret()
+
+	_, err = s.db.Exec(`
+		UPDATE webhooks
+		SET secret = ?, previous_secret = ?, previous_secret_expires_at = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`, newSecret, webhook.Secret, expiresAt, now, id, userID)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to rotate secret for webhook %s: %v", id, err)
+		return nil, err
+	}
+
+	webhook.PreviousSecret = webhook.Secret
+	webhook.PreviousSecretExpiresAt = &expiresAt
+	webhook.Secret = newSecret
+	webhook.UpdatedAt = now
+
+	utils.InfoLogger.Printf("Rotated secret for webhook %s, previous secret valid until %s", id, expiresAt.Format(time.RFC3339))
+	return webhook, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
 	var webhook models.Webhook
-	err := s.db.QueryRow("SELECT id, user_id, endpoint, secret, created_at, updated_at FROM webhooks WHERE endpoint = ?", endpoint).
-		Scan(&webhook.ID, &webhook.UserID, &webhook.Endpoint, &webhook.Secret, &webhook.CreatedAt, &webhook.UpdatedAt)
+	var previousSecret sql.NullString
+	var previousSecretExpiresAt sql.NullTime
+
+	err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.Label, &webhook.Endpoint, &webhook.Secret,
+		&previousSecret, &previousSecretExpiresAt, &webhook.CreatedAt, &webhook.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+
+	webhook.PreviousSecret = previousSecret.String
+	if previousSecretExpiresAt.Valid {
+		webhook.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
+
 	return &webhook, nil
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/troneras/ghost-listmonk-connector/models"
+	"github.com/troneras/ghost-listmonk-connector/utils"
+)
+
+// Reason codes recorded in webhook_logs when a delivery is rejected
+const (
+	RejectMissingSignature     = "missing_signature"
+	RejectMalformedSignature   = "malformed_signature"
+	RejectSignatureMismatch    = "signature_mismatch"
+	RejectTimestampOutOfWindow = "timestamp_out_of_window"
+	RejectReplayedSignature    = "replayed_signature"
+)
+
+// WebhookSignatureVerifier checks Ghost webhook signatures and rejects
+// deliveries that are stale or whose signature has already been seen.
+type WebhookSignatureVerifier struct {
+	redis     *redis.Client
+	tolerance time.Duration
+}
+
+func NewWebhookSignatureVerifier(redisAddr string, tolerance time.Duration) *WebhookSignatureVerifier {
+	return &WebhookSignatureVerifier{
+		redis:     redis.NewClient(&redis.Options{Addr: redisAddr}),
+		tolerance: tolerance,
+	}
+}
+
+// Verify validates the x-ghost-signature header ("sha256=<hex>, t=<ms>")
+// against the webhook secret. Errors are *utils.CustomError values whose Code
+// is one of the Reject* reason codes.
+func (v *WebhookSignatureVerifier) Verify(ctx context.Context, webhook *models.Webhook, header string, body []byte) error {
+	if header == "" {
+		return utils.NewError(RejectMissingSignature, "Missing signature header")
+	}
+
+	signature, timestamp, err := parseSignatureHeader(header)
+	if err != nil {
+		return utils.NewError(RejectMalformedSignature, err.Error())
+	}
+
+	// During a secret rotation both the new and the previous secret are valid
+	matched := false
+	for _, secret := range webhook.ValidSecrets(time.Now()) {
+		expected := SignWebhookPayload(body, secret, timestamp)
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return utils.NewError(RejectSignatureMismatch, "Signature does not match")
+	}
+
+	signedAt, err := parseSignatureTimestamp(timestamp)
+	if err != nil {
+		return utils.NewError(RejectMalformedSignature, err.Error())
+	}
+	if drift := time.Since(signedAt); drift > v.tolerance || drift < -v.tolerance {
+		return utils.NewError(RejectTimestampOutOfWindow, fmt.Sprintf("Signature timestamp is outside the %s tolerance window", v.tolerance))
+	}
+
+	// Remember the signature for twice the tolerance so it cannot be reused
+	// while its timestamp is still acceptable.
+	key := fmt.Sprintf("webhook_signature:%s:%s", webhook.ID, signature)
+	fresh, err := v.redis.SetNX(ctx, key, 1, 2*v.tolerance).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record webhook signature: %w", err)
+	}
+	if !fresh {
+		return utils.NewError(RejectReplayedSignature, "Signature has already been used")
+	}
+
+	return nil
+}
+
+// SignWebhookPayload computes the hex HMAC Ghost sends for a payload
+func SignWebhookPayload(body []byte, secret string, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	mac.Write([]byte(timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader builds an x-ghost-signature header value for a payload
+func SignatureHeader(body []byte, secret string, signedAt time.Time) string {
+	timestamp := strconv.FormatInt(signedAt.UnixMilli(), 10)
+	return fmt.Sprintf("sha256=%s, t=%s", SignWebhookPayload(body, secret, timestamp), timestamp)
+}
+
+// DedupOverrideToken authorises a signed delivery to bypass deduplication.
+// It is bound to the signature header, which can only be used once, so a
+// token cannot be reused for another delivery.
+func DedupOverrideToken(secret string, signatureHeader string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("dedup-override:" + signatureHeader))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDedupOverride checks a token produced by DedupOverrideToken
+func (v *WebhookSignatureVerifier) VerifyDedupOverride(webhook *models.Webhook, signatureHeader string, token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, secret := range webhook.ValidSecrets(time.Now()) {
+		if hmac.Equal([]byte(token), []byte(DedupOverrideToken(secret, signatureHeader))) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSignatureHeader(header string) (string, string, error) {
+	parts := strings.Split(header, ", ")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "sha256=") || !strings.HasPrefix(parts[1], "t=") {
+		return "", "", fmt.Errorf("invalid signature format")
+	}
+	return strings.TrimPrefix(parts[0], "sha256="), strings.TrimPrefix(parts[1], "t="), nil
+}
+
+// parseSignatureTimestamp reads the t= value, which Ghost sends in
+// milliseconds. Second precision timestamps are accepted as well.
+func parseSignatureTimestamp(timestamp string) (time.Time, error) {
+	value, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid signature timestamp: %s", timestamp)
+	}
+	if value < 1e12 {
+		return time.Unix(value, 0), nil
+	}
+	return time.UnixMilli(value), nil
+}
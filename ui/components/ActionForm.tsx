@@ -17,10 +17,12 @@ import {
 } from "@/components/ui/select";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
 import { Trash2 } from "lucide-react";
 import { CampaignActionFields } from "./CampaignActionFields";
 import { ManageSubscriberActionFields } from "./ManageSubscriberActionFields";
 import { TransactionalEmailActionFields } from "./TransactionalEmailActionFields";
+import { HttpRequestActionFields } from "./HttpRequestActionFields";
 import { ListmonkList, ListmonkTemplate } from "@/lib/types";
 
 interface ActionFormProps {
@@ -85,6 +87,19 @@ export function ActionForm({
                   <SelectItem value="create_campaign">
                     Create Campaign
                   </SelectItem>
+                  <SelectItem value="remove_from_lists">
+                    Remove From Lists
+                  </SelectItem>
+                  <SelectItem value="unsubscribe_subscriber">
+                    Unsubscribe From All Lists
+                  </SelectItem>
+                  <SelectItem value="blocklist_subscriber">
+                    Blocklist Subscriber
+                  </SelectItem>
+                  <SelectItem value="delete_subscriber">
+                    Delete Subscriber
+                  </SelectItem>
+                  <SelectItem value="http_request">HTTP Request</SelectItem>
                 </SelectContent>
               </Select>
               {actionType === "manage_subscriber" && (
@@ -97,12 +112,192 @@ export function ActionForm({
                   with the same name as the newsletters on Ghost.
                 </FormDescription>
               )}
+              {actionType === "delete_subscriber" && (
+                <FormDescription>
+                  The subscriber is permanently deleted from Listmonk together
+                  with their subscription history.
+                </FormDescription>
+              )}
+
+              <FormMessage />
+            </FormItem>
+          )}
+        />
+
+        <FormField
+          control={form.control}
+          name={`actions.${index}.delay`}
+          render={({ field }) => (
+            <FormItem>
+              <FormLabel>Delay</FormLabel>
+              <FormControl>
+                <Input
+                  {...field}
+                  value={field.value ?? ""}
+                  placeholder="e.g., 0s, 3d, 10d"
+                />
+              </FormControl>
+              <FormDescription>
+                Runs this action this long after the Son delay. Give each
+                action a different delay to send a sequence, e.g. a welcome
+                email now and a follow-up at &quot;3d&quot;.
+              </FormDescription>
+              <FormMessage />
+            </FormItem>
+          )}
+        />
 
+        <FormField
+          control={form.control}
+          name={`actions.${index}.anchor`}
+          render={({ field }) => (
+            <FormItem>
+              <FormLabel>Count Delay From</FormLabel>
+              <FormControl>
+                <Input
+                  {...field}
+                  value={field.value ?? ""}
+                  placeholder="e.g., member.created_at, post.published_at"
+                />
+              </FormControl>
+              <FormDescription>
+                A timestamp in the webhook the delay counts from instead of
+                the Son delay. Use a negative delay such as &quot;-1h&quot; to
+                run before it.
+              </FormDescription>
               <FormMessage />
             </FormItem>
           )}
         />
 
+        <div className="grid grid-cols-3 gap-4">
+          <FormField
+            control={form.control}
+            name={`actions.${index}.send_window.start`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Send From</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value ?? ""} placeholder="09:00" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          <FormField
+            control={form.control}
+            name={`actions.${index}.send_window.end`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Send Until</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value ?? ""} placeholder="18:00" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          <FormField
+            control={form.control}
+            name={`actions.${index}.send_window.timezone`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Fallback Timezone</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value ?? ""} placeholder="UTC" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+        </div>
+        <FormDescription>
+          Optionally hold the action until this time of day, in the
+          member&apos;s timezone from Ghost or the fallback timezone otherwise.
+        </FormDescription>
+
+        <div className="grid grid-cols-4 gap-4">
+          <FormField
+            control={form.control}
+            name={`actions.${index}.retry.max_retries`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Retries</FormLabel>
+                <FormControl>
+                  <Input
+                    type="number"
+                    min={0}
+                    max={25}
+                    {...field}
+                    value={field.value ?? ""}
+                    placeholder="3"
+                  />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          <FormField
+            control={form.control}
+            name={`actions.${index}.retry.backoff`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Backoff</FormLabel>
+                <Select
+                  onValueChange={(value: string) =>
+                    field.onChange(value === "default" ? undefined : value)
+                  }
+                  value={field.value ?? "default"}
+                >
+                  <FormControl>
+                    <SelectTrigger>
+                      <SelectValue />
+                    </SelectTrigger>
+                  </FormControl>
+                  <SelectContent>
+                    <SelectItem value="default">Default</SelectItem>
+                    <SelectItem value="exponential">Exponential</SelectItem>
+                    <SelectItem value="linear">Linear</SelectItem>
+                    <SelectItem value="fixed">Fixed</SelectItem>
+                  </SelectContent>
+                </Select>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          <FormField
+            control={form.control}
+            name={`actions.${index}.retry.backoff_delay`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>First Retry After</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value ?? ""} placeholder="30s" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          <FormField
+            control={form.control}
+            name={`actions.${index}.retry.timeout`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Timeout</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value ?? ""} placeholder="30m" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+        </div>
+        <FormDescription>
+          How often a failing action is retried and how long each attempt may
+          take. Actions that run out of retries are listed under Failed
+          Actions, where they can be retried or discarded.
+        </FormDescription>
+
         {actionType === "create_campaign" && (
           <CampaignActionFields
             form={form}
@@ -120,6 +315,19 @@ export function ActionForm({
           />
         )}
 
+        {actionType === "remove_from_lists" && (
+          <ManageSubscriberActionFields
+            form={form}
+            index={index}
+            lists={lists}
+            removing
+          />
+        )}
+
+        {actionType === "http_request" && (
+          <HttpRequestActionFields form={form} index={index} />
+        )}
+
         {actionType === "send_transactional_email" && (
           <TransactionalEmailActionFields
             form={form}
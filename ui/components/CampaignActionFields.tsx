@@ -28,6 +28,7 @@ import {
   TooltipTrigger,
 } from "@/components/ui/tooltip";
 import DatePicker from "react-datepicker";
+import { CampaignTemplatePreview } from "./CampaignTemplatePreview";
 import "react-datepicker/dist/react-datepicker.css";
 
 export function CampaignActionFields({
@@ -36,6 +37,9 @@ export function CampaignActionFields({
   lists,
   templates,
 }: CampaignActionFieldsProps) {
+  const approvalRequired = form.watch(
+    `actions.${index}.parameters.approval_required`
+  );
   const placeholderTemplate = `
   <h1>New Blog Post: {{ .Post.Title }}</h1>
   
@@ -47,9 +51,9 @@ export function CampaignActionFields({
     {{ .Post.Html }}
   </div>
   
-  <p>Read the full post at: <a href="https://yourblog.com/{{ .Post.Slug }}">{{ .Post.Title }}</a></p>
+  <p>Read the full post at: <a href="{{ .Post.URL }}">{{ .Post.Title }}</a></p>
   
-  <p>Published on: {{ .Post.PublishedAt }}</p>
+  <p>Published on {{ date "Jan 2, 2006" .Post.PublishedAt }} by {{ .Post.PrimaryAuthor.Name }}</p>
   `;
 
   return (
@@ -160,28 +164,63 @@ export function CampaignActionFields({
               <p>Available placeholders:</p>
               <ul className="list-disc pl-5 space-y-1">
                 <li>
-                  <code>{"{{ .Post.Title }}"}</code> - The title of the post
+                  <code>{"{{ .Post.Title }}"}</code>,{" "}
+                  <code>{"{{ .Post.URL }}"}</code>,{" "}
+                  <code>{"{{ .Post.Slug }}"}</code> - The post title, link and
+                  slug
                 </li>
                 <li>
-                  <code>{"{{ .Post.FeatureImage }}"}</code> - URL of the feature
-                  image
+                  <code>{"{{ .Post.Html }}"}</code>,{" "}
+                  <code>{"{{ .Post.PlainText }}"}</code> - The full content of
+                  the post
                 </li>
                 <li>
-                  <code>{"{{ .Post.CustomExcerpt }}"}</code> - The custom
-                  excerpt of the post
+                  <code>{"{{ .Post.Excerpt }}"}</code>,{" "}
+                  <code>{"{{ .Post.CustomExcerpt }}"}</code>,{" "}
+                  <code>{"{{ .Post.FeatureImage }}"}</code> - Excerpts and
+                  feature image URL
                 </li>
                 <li>
-                  <code>{"{{ .Post.Html }}"}</code> - The full HTML content of
-                  the post
+                  <code>{"{{ .Post.PrimaryAuthor.Name }}"}</code>,{" "}
+                  <code>{"{{ .Post.PrimaryTag.Name }}"}</code>,{" "}
+                  <code>{"{{ range .Post.Tags }}{{ .Name }}{{ end }}"}</code> -
+                  Authors and tags
                 </li>
                 <li>
-                  <code>{"{{ .Post.Slug }}"}</code> - The URL slug of the post
+                  <code>{"{{ .Post.ReadingTime }}"}</code>,{" "}
+                  <code>{"{{ .Post.Visibility }}"}</code>,{" "}
+                  <code>{"{{ .Post.PublishedAt }}"}</code> - Reading time in
+                  minutes, visibility and publication date
                 </li>
                 <li>
-                  <code>{"{{ .Post.PublishedAt }}"}</code> - The publication
-                  date of the post
+                  <code>{"{{ .Site.URL }}"}</code>,{" "}
+                  <code>{"{{ .Son.Name }}"}</code> - The site root and the Son
+                  sending the campaign
                 </li>
               </ul>
+              <p>Functions:</p>
+              <ul className="list-disc pl-5 space-y-1">
+                <li>
+                  <code>{'{{ date "Jan 2, 2006" .Post.PublishedAt }}'}</code> -
+                  Format a date
+                </li>
+                <li>
+                  <code>{"{{ truncate 140 .Post.Excerpt }}"}</code> - Shorten
+                  text
+                </li>
+                <li>
+                  <code>{'{{ absURL .Site.URL "/tag/news/" }}'}</code> - Make a
+                  link absolute
+                </li>
+                <li>
+                  <code>{'{{ default "Team" .Post.PrimaryAuthor.Name }}'}</code>{" "}
+                  - Fall back when a value is empty
+                </li>
+              </ul>
+              <p>
+                The subject accepts the same placeholders. Templates are checked
+                when the Son is saved.
+              </p>
             </FormDescription>
             <FormMessage />
           </FormItem>
@@ -213,6 +252,8 @@ export function CampaignActionFields({
         </Tooltip>
       </TooltipProvider>
 
+      <CampaignTemplatePreview form={form} index={index} />
+
       <FormField
         control={form.control}
         name={`actions.${index}.parameters.lists`}
@@ -355,6 +396,71 @@ export function CampaignActionFields({
           </FormItem>
         )}
       />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.approval_required`}
+        render={({ field }) => (
+          <FormItem className="flex flex-row items-center justify-between rounded-lg border p-4">
+            <div className="space-y-0.5">
+              <FormLabel className="text-base">Require Approval</FormLabel>
+              <FormDescription>
+                Leave the campaign as a draft until someone approves it on the
+                Approvals page.
+              </FormDescription>
+            </div>
+            <FormControl>
+              <Switch
+                checked={!!field.value}
+                onCheckedChange={field.onChange}
+              />
+            </FormControl>
+          </FormItem>
+        )}
+      />
+
+      {approvalRequired && (
+        <>
+          <FormField
+            control={form.control}
+            name={`actions.${index}.parameters.test_emails`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Test Emails</FormLabel>
+                <FormControl>
+                  <Input
+                    {...field}
+                    value={field.value || ""}
+                    placeholder="editor@example.com, owner@example.com"
+                  />
+                </FormControl>
+                <FormDescription>
+                  Comma-separated addresses that receive a test of the draft.
+                  They must exist as subscribers in Listmonk.
+                </FormDescription>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+
+          <FormField
+            control={form.control}
+            name={`actions.${index}.parameters.approval_expires_in`}
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Approval Expires After</FormLabel>
+                <FormControl>
+                  <Input {...field} value={field.value || ""} placeholder="72h" />
+                </FormControl>
+                <FormDescription>
+                  Drafts nobody approves within this time are discarded.
+                </FormDescription>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+        </>
+      )}
     </div>
   );
 }
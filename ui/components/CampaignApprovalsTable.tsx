@@ -0,0 +1,105 @@
+import React from "react";
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from "@/components/ui/table";
+import { Button } from "@/components/ui/button";
+import { Card, CardHeader, CardTitle, CardContent } from "@/components/ui/card";
+import { useToast } from "@/components/ui/use-toast";
+import { useCampaignApprovals } from "@/hooks/useCampaignApprovals";
+import { CampaignApproval } from "@/lib/types";
+
+export const CampaignApprovalsTable: React.FC = () => {
+  const { approvals, loading, error, decide } = useCampaignApprovals();
+  const { toast } = useToast();
+
+  const handleDecision = async (
+    approval: CampaignApproval,
+    decision: "approve" | "reject"
+  ) => {
+    try {
+      await decide(approval.id, decision);
+      toast({
+        title: decision === "approve" ? "Campaign Approved" : "Campaign Rejected",
+        description:
+          decision === "approve"
+            ? `${approval.campaign_name} has been scheduled.`
+            : `${approval.campaign_name} has been discarded.`,
+        variant: "default",
+      });
+    } catch (error) {
+      toast({
+        title: "Error",
+        description: `Failed to ${decision} ${approval.campaign_name}.`,
+        variant: "destructive",
+      });
+    }
+  };
+
+  if (loading) return <div>Loading...</div>;
+  if (error) return <div>Error: {error.message}</div>;
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>Pending Approvals</CardTitle>
+      </CardHeader>
+      <CardContent>
+        {approvals.length === 0 ? (
+          <p>No campaigns are waiting for approval.</p>
+        ) : (
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>Campaign</TableHead>
+                <TableHead>Test Sent To</TableHead>
+                <TableHead>Created</TableHead>
+                <TableHead>Expires</TableHead>
+                <TableHead></TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {approvals.map((approval) => (
+                <TableRow key={approval.id}>
+                  <TableCell>
+                    {approval.campaign_name} (#{approval.campaign_id})
+                  </TableCell>
+                  <TableCell>
+                    {approval.test_emails.length > 0
+                      ? approval.test_emails.join(", ")
+                      : "N/A"}
+                  </TableCell>
+                  <TableCell>
+                    {new Date(approval.created_at).toLocaleString()}
+                  </TableCell>
+                  <TableCell>
+                    {new Date(approval.expires_at).toLocaleString()}
+                  </TableCell>
+                  <TableCell className="space-x-2 text-right">
+                    <Button
+                      size="sm"
+                      onClick={() => handleDecision(approval, "approve")}
+                    >
+                      Approve
+                    </Button>
+                    <Button
+                      size="sm"
+                      variant="outline"
+                      onClick={() => handleDecision(approval, "reject")}
+                    >
+                      Reject
+                    </Button>
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        )}
+      </CardContent>
+    </Card>
+  );
+};
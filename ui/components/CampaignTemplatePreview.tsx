@@ -0,0 +1,122 @@
+import React, { useState } from "react";
+import axios from "axios";
+import { UseFormReturn } from "react-hook-form";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Alert, AlertDescription, AlertTitle } from "@/components/ui/alert";
+import { apiClient } from "@/lib/api-client";
+import { EditableSon, TemplateError, TemplatePreview } from "@/lib/types";
+
+interface CampaignTemplatePreviewProps {
+  form: UseFormReturn<EditableSon>;
+  index: number;
+}
+
+function describe(issue: TemplateError) {
+  const position = issue.line
+    ? ` line ${issue.line}${issue.column ? `:${issue.column}` : ""}`
+    : "";
+  return `${issue.template}${position}: ${issue.message}`;
+}
+
+// Renders the campaign subject and body against the trigger sample payload,
+// or against a logged webhook when its ID is given.
+export function CampaignTemplatePreview({
+  form,
+  index,
+}: CampaignTemplatePreviewProps) {
+  const [webhookLogId, setWebhookLogId] = useState("");
+  const [preview, setPreview] = useState<TemplatePreview | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [loading, setLoading] = useState(false);
+
+  const renderPreview = async () => {
+    try {
+      setLoading(true);
+      const response = await apiClient.post<{ data: TemplatePreview }>(
+        "/template-preview",
+        {
+          subject: form.getValues(`actions.${index}.parameters.subject`) ?? "",
+          body: form.getValues(`actions.${index}.parameters.body`) ?? "",
+          trigger: form.getValues("trigger"),
+          son_name: form.getValues("name"),
+          webhook_log_id: webhookLogId || undefined,
+        }
+      );
+      setPreview(response.data.data);
+      setError(null);
+    } catch (err) {
+      setError(
+        (axios.isAxiosError(err) && err.response?.data?.error) ||
+          "Failed to render preview"
+      );
+      setPreview(null);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="space-y-2 rounded-md border p-4">
+      <div className="flex gap-2">
+        <Input
+          value={webhookLogId}
+          onChange={(e) => setWebhookLogId(e.target.value)}
+          placeholder="Webhook log ID (optional, defaults to the sample payload)"
+        />
+        <Button
+          type="button"
+          variant="outline"
+          onClick={renderPreview}
+          disabled={loading}
+        >
+          {loading ? "Rendering..." : "Preview"}
+        </Button>
+      </div>
+
+      {error && (
+        <Alert variant="destructive">
+          <AlertDescription>{error}</AlertDescription>
+        </Alert>
+      )}
+
+      {preview && (
+        <>
+          {preview.errors.length > 0 && (
+            <Alert variant="destructive">
+              <AlertTitle>Template errors</AlertTitle>
+              <AlertDescription>
+                <ul className="list-disc pl-5">
+                  {preview.errors.map((issue, i) => (
+                    <li key={i}>{describe(issue)}</li>
+                  ))}
+                </ul>
+              </AlertDescription>
+            </Alert>
+          )}
+          {preview.warnings.length > 0 && (
+            <Alert>
+              <AlertTitle>Empty fields</AlertTitle>
+              <AlertDescription>
+                <ul className="list-disc pl-5">
+                  {preview.warnings.map((issue, i) => (
+                    <li key={i}>{describe(issue)}</li>
+                  ))}
+                </ul>
+              </AlertDescription>
+            </Alert>
+          )}
+          <p className="text-sm">
+            <span className="font-medium">Subject:</span> {preview.subject}
+          </p>
+          <iframe
+            title="Campaign preview"
+            className="h-96 w-full rounded border bg-white"
+            sandbox=""
+            srcDoc={preview.body}
+          />
+        </>
+      )}
+    </div>
+  );
+}
@@ -16,6 +16,7 @@ import { ChartContainer, ChartConfig } from "@/components/ui/chart";
 import { DashboardSkeleton } from "./DashboardSKeleton";
 import { useRecentActivity } from "@/hooks/useRecentActivity";
 import { useSonStats } from "@/hooks/useSonStats";
+import { useCampaignStats } from "@/hooks/useCampaignStats";
 import {
   Select,
   SelectContent,
@@ -23,6 +24,14 @@ import {
   SelectTrigger,
   SelectValue,
 } from "@/components/ui/select";
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from "@/components/ui/table";
 
 const chartConfig = {
   executions: {
@@ -49,6 +58,9 @@ const timeframeOptions = [
 
 export default function Dashboard() {
   const [timeframe, setTimeframe] = useState("24h");
+  const [campaignGrouping, setCampaignGrouping] = useState<"sons" | "posts">(
+    "sons"
+  );
   const {
     activities,
     loading: activitiesLoading,
@@ -59,6 +71,8 @@ export default function Dashboard() {
     loading: statsLoading,
     error: statsError,
   } = useSonStats(timeframe);
+  const { stats: campaignStats, error: campaignStatsError } =
+    useCampaignStats(campaignGrouping);
 
   if (activitiesLoading || statsLoading) {
     return <DashboardSkeleton />;
@@ -127,6 +141,60 @@ export default function Dashboard() {
           </CardContent>
         </Card>
       </div>
+
+      <Card>
+        <CardHeader className="flex flex-row items-center justify-between space-y-0 pb-2">
+          <CardTitle>Campaign Engagement</CardTitle>
+          <Select
+            value={campaignGrouping}
+            onValueChange={(value) =>
+              setCampaignGrouping(value as "sons" | "posts")
+            }
+          >
+            <SelectTrigger className="w-[180px]">
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              <SelectItem value="sons">Per Son</SelectItem>
+              <SelectItem value="posts">Per Post</SelectItem>
+            </SelectContent>
+          </Select>
+        </CardHeader>
+        <CardContent>
+          {campaignStatsError && <div>Error loading campaign stats</div>}
+          {!campaignStatsError && campaignStats.length === 0 && (
+            <div>No campaigns created yet</div>
+          )}
+          {campaignStats.length > 0 && (
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>
+                    {campaignGrouping === "sons" ? "Son" : "Post"}
+                  </TableHead>
+                  <TableHead>Campaigns</TableHead>
+                  <TableHead>Sent</TableHead>
+                  <TableHead>Views</TableHead>
+                  <TableHead>Clicks</TableHead>
+                  <TableHead>Bounces</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {campaignStats.map((stat) => (
+                  <TableRow key={stat.son_id ?? stat.post_id}>
+                    <TableCell>{stat.name || stat.post_id}</TableCell>
+                    <TableCell>{stat.campaigns}</TableCell>
+                    <TableCell>{stat.sent}</TableCell>
+                    <TableCell>{stat.views}</TableCell>
+                    <TableCell>{stat.clicks}</TableCell>
+                    <TableCell>{stat.bounces}</TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          )}
+        </CardContent>
+      </Card>
     </div>
   );
 }
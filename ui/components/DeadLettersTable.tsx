@@ -0,0 +1,192 @@
+import React, { useState } from "react";
+import Link from "next/link";
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from "@/components/ui/table";
+import { Button } from "@/components/ui/button";
+import { Card, CardHeader, CardTitle, CardContent } from "@/components/ui/card";
+import {
+  Dialog,
+  DialogContent,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog";
+import { useToast } from "@/components/ui/use-toast";
+import { useDeadLetters } from "@/hooks/useDeadLetters";
+import { DeadLetter } from "@/lib/types";
+
+export const DeadLettersTable: React.FC = () => {
+  const { deadLetters, total, loading, error, retry, discard } =
+    useDeadLetters();
+  const [selected, setSelected] = useState<string[]>([]);
+  const [viewing, setViewing] = useState<DeadLetter | null>(null);
+  const { toast } = useToast();
+
+  const toggle = (id: string) =>
+    setSelected((prev) =>
+      prev.includes(id) ? prev.filter((s) => s !== id) : [...prev, id]
+    );
+
+  const toggleAll = () =>
+    setSelected((prev) =>
+      prev.length === deadLetters.length ? [] : deadLetters.map((d) => d.id)
+    );
+
+  const handle = async (operation: "retry" | "discard", ids?: string[]) => {
+    const label = operation === "retry" ? "retried" : "discarded";
+    try {
+      await (operation === "retry" ? retry(ids) : discard(ids));
+      setSelected([]);
+      toast({
+        title: operation === "retry" ? "Actions Retried" : "Actions Discarded",
+        description: ids
+          ? `${ids.length} action(s) ${label}.`
+          : `All failed actions ${label}.`,
+        variant: "default",
+      });
+    } catch (error) {
+      toast({
+        title: "Error",
+        description: `Some actions could not be ${label}.`,
+        variant: "destructive",
+      });
+    }
+  };
+
+  if (loading) return <div>Loading...</div>;
+  if (error) return <div>Error: {error.message}</div>;
+
+  return (
+    <Card>
+      <CardHeader className="flex flex-row items-center justify-between space-y-0">
+        <CardTitle>Failed Actions ({total})</CardTitle>
+        {deadLetters.length > 0 && (
+          <div className="space-x-2">
+            <Button
+              size="sm"
+              disabled={selected.length === 0}
+              onClick={() => handle("retry", selected)}
+            >
+              Retry Selected
+            </Button>
+            <Button
+              size="sm"
+              variant="outline"
+              disabled={selected.length === 0}
+              onClick={() => handle("discard", selected)}
+            >
+              Discard Selected
+            </Button>
+            <Button size="sm" variant="secondary" onClick={() => handle("retry")}>
+              Retry All
+            </Button>
+            <Button size="sm" variant="ghost" onClick={() => handle("discard")}>
+              Discard All
+            </Button>
+          </div>
+        )}
+      </CardHeader>
+      <CardContent>
+        {deadLetters.length === 0 ? (
+          <p>No actions have run out of retries.</p>
+        ) : (
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>
+                  <input
+                    type="checkbox"
+                    aria-label="Select all"
+                    checked={selected.length === deadLetters.length}
+                    onChange={toggleAll}
+                  />
+                </TableHead>
+                <TableHead>Son</TableHead>
+                <TableHead>Action</TableHead>
+                <TableHead>Last Error</TableHead>
+                <TableHead>Failed At</TableHead>
+                <TableHead>Retries</TableHead>
+                <TableHead></TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {deadLetters.map((deadLetter) => (
+                <TableRow key={deadLetter.id}>
+                  <TableCell>
+                    <input
+                      type="checkbox"
+                      aria-label={`Select ${deadLetter.id}`}
+                      checked={selected.includes(deadLetter.id)}
+                      onChange={() => toggle(deadLetter.id)}
+                    />
+                  </TableCell>
+                  <TableCell>
+                    <Link
+                      href={`/sons/${deadLetter.son_id}`}
+                      className="text-blue-600 hover:underline"
+                    >
+                      {deadLetter.son_name || deadLetter.son_id}
+                    </Link>
+                  </TableCell>
+                  <TableCell>
+                    {deadLetter.action_index !== undefined
+                      ? `${deadLetter.action_index + 1}. `
+                      : ""}
+                    {deadLetter.action_type}
+                  </TableCell>
+                  <TableCell className="max-w-xs truncate" title={deadLetter.last_error}>
+                    {deadLetter.last_error}
+                  </TableCell>
+                  <TableCell>
+                    {new Date(deadLetter.last_failed_at).toLocaleString()}
+                  </TableCell>
+                  <TableCell>
+                    {deadLetter.retried}/{deadLetter.max_retry}
+                  </TableCell>
+                  <TableCell className="space-x-2 text-right">
+                    <Button
+                      size="sm"
+                      variant="link"
+                      onClick={() => setViewing(deadLetter)}
+                    >
+                      Payload
+                    </Button>
+                    <Button
+                      size="sm"
+                      onClick={() => handle("retry", [deadLetter.id])}
+                    >
+                      Retry
+                    </Button>
+                    <Button
+                      size="sm"
+                      variant="outline"
+                      onClick={() => handle("discard", [deadLetter.id])}
+                    >
+                      Discard
+                    </Button>
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        )}
+      </CardContent>
+
+      <Dialog open={!!viewing} onOpenChange={() => setViewing(null)}>
+        <DialogContent className="max-w-3xl">
+          <DialogHeader>
+            <DialogTitle>Payload of {viewing?.action_type}</DialogTitle>
+          </DialogHeader>
+          <pre className="max-h-[60vh] overflow-auto rounded bg-muted p-4 text-xs">
+            {JSON.stringify(viewing?.payload, null, 2)}
+          </pre>
+        </DialogContent>
+      </Dialog>
+    </Card>
+  );
+};
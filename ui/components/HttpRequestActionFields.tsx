@@ -0,0 +1,204 @@
+import React from "react";
+import { UseFormReturn } from "react-hook-form";
+import {
+  FormField,
+  FormItem,
+  FormLabel,
+  FormControl,
+  FormMessage,
+  FormDescription,
+} from "@/components/ui/form";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select";
+import { Input } from "@/components/ui/input";
+import { Textarea } from "@/components/ui/textarea";
+import { Button } from "@/components/ui/button";
+import { Trash2, Plus } from "lucide-react";
+
+interface HttpRequestActionFieldsProps {
+  form: UseFormReturn<any>;
+  index: number;
+}
+
+interface Header {
+  key: string;
+  value: string;
+}
+
+const methods = ["POST", "PUT", "PATCH", "GET", "DELETE"];
+
+export function HttpRequestActionFields({
+  form,
+  index,
+}: HttpRequestActionFieldsProps) {
+  const placeholderBody = `{
+  "email": {{ json .member.current.email }},
+  "name": {{ json .member.current.name }}
+}`;
+
+  return (
+    <div className="space-y-4">
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.url`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>URL</FormLabel>
+            <FormControl>
+              <Input {...field} placeholder="https://example.com/hooks/ghost" />
+            </FormControl>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.method`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Method</FormLabel>
+            <Select onValueChange={field.onChange} value={field.value || "POST"}>
+              <FormControl>
+                <SelectTrigger>
+                  <SelectValue />
+                </SelectTrigger>
+              </FormControl>
+              <SelectContent>
+                {methods.map((method) => (
+                  <SelectItem key={method} value={method}>
+                    {method}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.headers`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Headers</FormLabel>
+            <div className="space-y-2">
+              {((field.value as Header[]) || []).map((header, headerIndex) => (
+                <div key={headerIndex} className="flex items-center space-x-2">
+                  <Input
+                    placeholder="Key"
+                    value={header.key}
+                    onChange={(e: React.ChangeEvent<HTMLInputElement>) => {
+                      const newHeaders = [...(field.value as Header[])];
+                      newHeaders[headerIndex].key = e.target.value;
+                      field.onChange(newHeaders);
+                    }}
+                  />
+                  <Input
+                    placeholder="Value"
+                    value={header.value}
+                    onChange={(e: React.ChangeEvent<HTMLInputElement>) => {
+                      const newHeaders = [...(field.value as Header[])];
+                      newHeaders[headerIndex].value = e.target.value;
+                      field.onChange(newHeaders);
+                    }}
+                  />
+                  <Button
+                    type="button"
+                    variant="ghost"
+                    size="sm"
+                    onClick={() => {
+                      const newHeaders = (field.value as Header[]).filter(
+                        (_, i) => i !== headerIndex
+                      );
+                      field.onChange(newHeaders);
+                    }}
+                  >
+                    <Trash2 className="h-4 w-4" />
+                  </Button>
+                </div>
+              ))}
+              <Button
+                type="button"
+                variant="outline"
+                size="sm"
+                onClick={() => {
+                  const newHeaders = [
+                    ...((field.value as Header[]) || []),
+                    { key: "", value: "" },
+                  ];
+                  field.onChange(newHeaders);
+                }}
+              >
+                <Plus className="mr-2 h-4 w-4" /> Add Header
+              </Button>
+            </div>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.body`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Body</FormLabel>
+            <FormControl>
+              <Textarea
+                {...field}
+                placeholder={placeholderBody}
+                className="min-h-[150px] font-mono"
+              />
+            </FormControl>
+            <FormDescription>
+              A Go template over the webhook payload. Use{" "}
+              <code>{"{{ json .member.current.email }}"}</code> to insert a
+              value as JSON.
+            </FormDescription>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.secret`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Signing Secret</FormLabel>
+            <FormControl>
+              <Input {...field} type="password" placeholder="Optional" />
+            </FormControl>
+            <FormDescription>
+              When set, requests carry a{" "}
+              <code>sha256=&lt;hmac&gt;, t=&lt;timestamp&gt;</code> signature
+              of the body and timestamp, in the same format Ghost uses.
+            </FormDescription>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+
+      <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.signature_header`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Signature Header</FormLabel>
+            <FormControl>
+              <Input {...field} placeholder="X-Signature" />
+            </FormControl>
+            <FormMessage />
+          </FormItem>
+        )}
+      />
+    </div>
+  );
+}
@@ -16,13 +16,25 @@ import {
 } from "@/components/ui/select";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
-import { X } from "lucide-react";
-import { ManageSubscriberActionFieldsProps } from "@/lib/types";
+import { Input } from "@/components/ui/input";
+import { Plus, Trash2, X } from "lucide-react";
+import { AttributeMapping, ManageSubscriberActionFieldsProps } from "@/lib/types";
+
+const attributeTypes = [
+  { value: "auto", label: "As is" },
+  { value: "string", label: "Text" },
+  { value: "number", label: "Number" },
+  { value: "boolean", label: "Boolean" },
+  { value: "date", label: "Date" },
+  { value: "timestamp", label: "Unix timestamp" },
+  { value: "list", label: "List" },
+];
 
 export function ManageSubscriberActionFields({
   form,
   index,
   lists,
+  removing = false,
 }: ManageSubscriberActionFieldsProps) {
   return (
     <div className="space-y-4">
@@ -75,13 +87,130 @@ export function ManageSubscriberActionFields({
               })}
             </div>
             <FormDescription>
-              Default lists to add this user on creation. (Apart from the lists
-              for the ghost blog newsletters) p.e. &quot;New Subscribers&quot;
+              {removing
+                ? "Lists to remove the subscriber from."
+                : <>Default lists to add this user on creation. (Apart from the lists
+              for the ghost blog newsletters) p.e. &quot;New Subscribers&quot;</>}
             </FormDescription>
             <FormMessage />
           </FormItem>
         )}
       />
+      {!removing && <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.list_mode`}
+        render={({ field }) => (
+          <FormItem>
+            <FormLabel>Existing subscribers</FormLabel>
+            <Select
+              onValueChange={field.onChange}
+              defaultValue={field.value || "merge"}
+            >
+              <FormControl>
+                <SelectTrigger>
+                  <SelectValue />
+                </SelectTrigger>
+              </FormControl>
+              <SelectContent>
+                <SelectItem value="merge">Keep their lists and add these</SelectItem>
+                <SelectItem value="replace">Replace their lists with these</SelectItem>
+              </SelectContent>
+            </Select>
+            <FormDescription>
+              How to update the lists of a subscriber that already exists in
+              Listmonk.
+            </FormDescription>
+            <FormMessage />
+          </FormItem>
+        )}
+      />}
+      {!removing && <FormField
+        control={form.control}
+        name={`actions.${index}.parameters.attribute_mappings`}
+        render={({ field }) => {
+          const mappings = (field.value as AttributeMapping[]) || [];
+          const update = (mappingIndex: number, changes: Partial<AttributeMapping>) => {
+            const newMappings = [...mappings];
+            newMappings[mappingIndex] = { ...newMappings[mappingIndex], ...changes };
+            field.onChange(newMappings);
+          };
+          return (
+            <FormItem>
+              <FormLabel>Attributes</FormLabel>
+              <div className="space-y-2">
+                {mappings.map((mapping, mappingIndex) => (
+                  <div key={mappingIndex} className="flex items-center space-x-2">
+                    <Input
+                      placeholder="Ghost field, e.g. labels"
+                      value={mapping.source}
+                      onChange={(e: React.ChangeEvent<HTMLInputElement>) =>
+                        update(mappingIndex, { source: e.target.value })
+                      }
+                    />
+                    <Input
+                      placeholder="Listmonk attribute"
+                      value={mapping.attribute}
+                      onChange={(e: React.ChangeEvent<HTMLInputElement>) =>
+                        update(mappingIndex, { attribute: e.target.value })
+                      }
+                    />
+                    <Select
+                      value={mapping.type || "auto"}
+                      onValueChange={(value) =>
+                        update(mappingIndex, { type: value === "auto" ? undefined : value })
+                      }
+                    >
+                      <SelectTrigger className="w-40">
+                        <SelectValue />
+                      </SelectTrigger>
+                      <SelectContent>
+                        {attributeTypes.map((type) => (
+                          <SelectItem key={type.value} value={type.value}>
+                            {type.label}
+                          </SelectItem>
+                        ))}
+                      </SelectContent>
+                    </Select>
+                    <Input
+                      placeholder="Default"
+                      value={mapping.default ?? ""}
+                      onChange={(e: React.ChangeEvent<HTMLInputElement>) =>
+                        update(mappingIndex, { default: e.target.value || undefined })
+                      }
+                    />
+                    <Button
+                      type="button"
+                      variant="ghost"
+                      size="sm"
+                      onClick={() =>
+                        field.onChange(mappings.filter((_, i) => i !== mappingIndex))
+                      }
+                    >
+                      <Trash2 className="h-4 w-4" />
+                    </Button>
+                  </div>
+                ))}
+                <Button
+                  type="button"
+                  variant="outline"
+                  size="sm"
+                  onClick={() =>
+                    field.onChange([...mappings, { source: "", attribute: "" }])
+                  }
+                >
+                  <Plus className="mr-2 h-4 w-4" /> Add Attribute
+                </Button>
+              </div>
+              <FormDescription>
+                Copy Ghost member fields (status, note, created_at, labels,
+                newsletters, tiers or a path like geolocation.country) into
+                Listmonk attributes. Without any, the member location is copied.
+              </FormDescription>
+              <FormMessage />
+            </FormItem>
+          );
+        }}
+      />}
       {/* Add more fields specific to manage_subscriber action if needed */}
     </div>
   );
@@ -7,6 +7,8 @@ import {
   ListTree,
   Settings,
   Activity,
+  CheckSquare,
+  AlertTriangle,
 } from "lucide-react";
 
 export const Sidebar = ({ isOpen }: { isOpen: boolean }) => {
@@ -16,12 +18,18 @@ export const Sidebar = ({ isOpen }: { isOpen: boolean }) => {
     { href: "/", label: "Dashboard", icon: LayoutDashboard },
     { href: "/sons/new", label: "Create Son", icon: ListPlus },
     { href: "/sons", label: "Manage Sons", icon: ListTree },
+    { href: "/approvals", label: "Approvals", icon: CheckSquare },
     { href: "/settings", label: "Settings", icon: Settings },
   ];
 
   const observabilityMenuItems = [
     { href: "/observability/webhooks", label: "Webhook Logs", icon: Activity },
     { href: "/observability/son-logs", label: "Son Logs", icon: Activity },
+    {
+      href: "/observability/dead-letters",
+      label: "Failed Actions",
+      icon: AlertTriangle,
+    },
   ];
 
   return (
@@ -17,9 +17,18 @@ import {
 } from "@/components/ui/select";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { SonDetailsFormProps } from "@/lib/types";
+import { exitConditions } from "@/lib/schemas";
 import { Switch } from "@/components/ui/switch";
 
+const exitConditionLabels: Record<(typeof exitConditions)[number], string> = {
+  member_deleted: "Member is deleted",
+  member_paid: "Member upgrades to paid",
+  member_unsubscribed: "Member unsubscribes",
+};
+
 export function SonDetailsForm({ form }: SonDetailsFormProps) {
+  const trigger = form.watch("trigger");
+
   return (
     <Card>
       <CardHeader>
@@ -48,7 +57,15 @@ export function SonDetailsForm({ form }: SonDetailsFormProps) {
           render={({ field }) => (
             <FormItem>
               <FormLabel>Trigger</FormLabel>
-              <Select onValueChange={field.onChange} defaultValue={field.value}>
+              <Select
+                onValueChange={(value) => {
+                  field.onChange(value);
+                  if (!value.startsWith("member_")) {
+                    form.setValue("exit_conditions", []);
+                  }
+                }}
+                defaultValue={field.value}
+              >
                 <FormControl>
                   <SelectTrigger>
                     <SelectValue placeholder="Select a trigger" />
@@ -60,6 +77,18 @@ export function SonDetailsForm({ form }: SonDetailsFormProps) {
                   <SelectItem value="member_updated">Member Updated</SelectItem>
                   <SelectItem value="post_published">Post Published</SelectItem>
                   <SelectItem value="post_scheduled">Post Scheduled</SelectItem>
+                  <SelectItem value="post_published_edited">Published Post Edited</SelectItem>
+                  <SelectItem value="post_unpublished">Post Unpublished</SelectItem>
+                  <SelectItem value="post_edited">Post Edited</SelectItem>
+                  <SelectItem value="post_deleted">Post Deleted</SelectItem>
+                  <SelectItem value="page_published">Page Published</SelectItem>
+                  <SelectItem value="page_published_edited">Published Page Edited</SelectItem>
+                  <SelectItem value="page_unpublished">Page Unpublished</SelectItem>
+                  <SelectItem value="page_edited">Page Edited</SelectItem>
+                  <SelectItem value="page_deleted">Page Deleted</SelectItem>
+                  <SelectItem value="tag_added">Tag Added</SelectItem>
+                  <SelectItem value="tag_deleted">Tag Deleted</SelectItem>
+                  <SelectItem value="site_changed">Site Changed</SelectItem>
                 </SelectContent>
               </Select>
               <FormDescription>
@@ -87,6 +116,42 @@ export function SonDetailsForm({ form }: SonDetailsFormProps) {
             </FormItem>
           )}
         />
+        {trigger.startsWith("member_") && (
+          <FormField
+            control={form.control}
+            name="exit_conditions"
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Exit Conditions</FormLabel>
+                <FormDescription>
+                  Cancel the delayed actions still waiting for a member when
+                  one of these happens.
+                </FormDescription>
+                {exitConditions.map((exit) => (
+                  <div
+                    key={exit}
+                    className="flex flex-row items-center justify-between rounded-lg border p-3"
+                  >
+                    <span className="text-sm">{exitConditionLabels[exit]}</span>
+                    <FormControl>
+                      <Switch
+                        checked={field.value?.includes(exit) ?? false}
+                        onCheckedChange={(checked) =>
+                          field.onChange(
+                            checked
+                              ? [...(field.value ?? []), exit]
+                              : (field.value ?? []).filter((e) => e !== exit)
+                          )
+                        }
+                      />
+                    </FormControl>
+                  </div>
+                ))}
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+        )}
         <FormField
           control={form.control}
           name="enabled"
@@ -25,7 +25,8 @@ import {
   PaginationPrevious,
   PaginationEllipsis,
 } from "@/components/ui/pagination";
-import { ActionExecutionLog } from "@/lib/types";
+import { Input } from "@/components/ui/input";
+import { ActionExecutionLog, ExecutionStep } from "@/lib/types";
 import { useToast } from "@/components/ui/use-toast";
 import Link from "next/link";
 import { WebhookDetailsDialog } from "@/components/WebhookDetailsDialog";
@@ -34,29 +35,49 @@ import { Badge } from "@/components/ui/badge";
 const StatusBadge: React.FC<{ status: string }> = ({ status }) => {
   let color = "bg-gray-500";
   switch (status.toLowerCase()) {
-    case "success":
+    case "succeeded":
       color = "bg-green-500";
       break;
-    case "failure":
+    case "failed":
       color = "bg-red-500";
       break;
-    case "warning":
+    case "partially_failed":
+      color = "bg-orange-500";
+      break;
+    case "cancelled":
       color = "bg-yellow-500";
       break;
     case "pending":
+    case "queued":
       color = "bg-blue-500";
       break;
+    case "running":
+      color = "bg-indigo-500";
+      break;
   }
-  return <Badge className={`${color} text-white`}>{status}</Badge>;
+  return (
+    <Badge className={`${color} text-white`}>{status.replace("_", " ")}</Badge>
+  );
 };
 
 const SonLogsPage: React.FC = () => {
-  const { logs, loading, error, pagination, fetchLogs, fetchActionLogs } =
-    useSonLogs();
+  const {
+    logs,
+    loading,
+    error,
+    pagination,
+    memberEmail,
+    setMemberEmail,
+    fetchLogs,
+    fetchActionLogs,
+    fetchSteps,
+  } = useSonLogs();
+  const [memberFilter, setMemberFilter] = useState(memberEmail);
   const [selectedExecution, setSelectedExecution] = useState<string | null>(
     null
   );
   const [actionLogs, setActionLogs] = useState<ActionExecutionLog[]>([]);
+  const [steps, setSteps] = useState<ExecutionStep[]>([]);
   const [selectedWebhookLogId, setSelectedWebhookLogId] = useState<
     string | null
   >(null);
@@ -73,8 +94,12 @@ const SonLogsPage: React.FC = () => {
   const openActionLogs = async (executionId: string) => {
     try {
       setSelectedExecution(executionId);
-      const actionLogsData = await fetchActionLogs(executionId);
+      const [actionLogsData, stepsData] = await Promise.all([
+        fetchActionLogs(executionId),
+        fetchSteps(executionId),
+      ]);
       setActionLogs(actionLogsData);
+      setSteps(stepsData);
     } catch (error) {
       toast({
         title: "Error",
@@ -99,10 +124,41 @@ const SonLogsPage: React.FC = () => {
           <CardTitle>Son Execution Logs</CardTitle>
         </CardHeader>
         <CardContent>
+          <form
+            className="mb-4 flex gap-2"
+            onSubmit={(e) => {
+              e.preventDefault();
+              setMemberEmail(memberFilter.trim());
+            }}
+          >
+            <Input
+              type="email"
+              value={memberFilter}
+              onChange={(e) => setMemberFilter(e.target.value)}
+              placeholder="Filter by member email"
+              className="max-w-sm"
+            />
+            <Button type="submit" variant="outline">
+              Filter
+            </Button>
+            {memberEmail && (
+              <Button
+                type="button"
+                variant="ghost"
+                onClick={() => {
+                  setMemberFilter("");
+                  setMemberEmail("");
+                }}
+              >
+                Clear
+              </Button>
+            )}
+          </form>
           <Table>
             <TableHeader>
               <TableRow>
                 <TableHead>Son Name</TableHead>
+                <TableHead>Member</TableHead>
                 <TableHead>Status</TableHead>
                 <TableHead>Executed At</TableHead>
                 <TableHead>Webhook</TableHead>
@@ -120,6 +176,22 @@ const SonLogsPage: React.FC = () => {
                       {log.sonName}
                     </Link>
                   </TableCell>
+                  <TableCell>
+                    {log.member_email ? (
+                      <Button
+                        variant="link"
+                        className="h-auto p-0"
+                        onClick={() => {
+                          setMemberFilter(log.member_email!);
+                          setMemberEmail(log.member_email!);
+                        }}
+                      >
+                        {log.member_email}
+                      </Button>
+                    ) : (
+                      "N/A"
+                    )}
+                  </TableCell>
                   <TableCell>
                     <StatusBadge status={log.status} />
                   </TableCell>
@@ -202,6 +274,42 @@ const SonLogsPage: React.FC = () => {
               Action Logs for Execution {selectedExecution}
             </DialogTitle>
           </DialogHeader>
+          {steps.length > 0 && (
+            <>
+              <h3 className="text-sm font-medium">Sequence</h3>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>Step</TableHead>
+                    <TableHead>Action Type</TableHead>
+                    <TableHead>Status</TableHead>
+                    <TableHead>Scheduled For</TableHead>
+                    <TableHead>Finished At</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {steps.map((step) => (
+                    <TableRow key={step.id}>
+                      <TableCell>{step.action_index + 1}</TableCell>
+                      <TableCell>{step.action_type}</TableCell>
+                      <TableCell title={step.error_message}>
+                        <StatusBadge status={step.status} />
+                      </TableCell>
+                      <TableCell>
+                        {new Date(step.scheduled_for).toLocaleString()}
+                      </TableCell>
+                      <TableCell>
+                        {step.finished_at
+                          ? new Date(step.finished_at).toLocaleString()
+                          : "N/A"}
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+              <h3 className="text-sm font-medium">Action Logs</h3>
+            </>
+          )}
           <Table>
             <TableHeader>
               <TableRow>
@@ -209,6 +317,7 @@ const SonLogsPage: React.FC = () => {
                 <TableHead>Status</TableHead>
                 <TableHead>Executed At</TableHead>
                 <TableHead>Error Message</TableHead>
+                <TableHead>Response</TableHead>
               </TableRow>
             </TableHeader>
             <TableBody>
@@ -222,6 +331,11 @@ const SonLogsPage: React.FC = () => {
                     {new Date(log.executed_at).toLocaleString()}
                   </TableCell>
                   <TableCell>{log.error_message || "N/A"}</TableCell>
+                  <TableCell title={log.response_body}>
+                    {log.campaign_id
+                      ? `Campaign #${log.campaign_id}`
+                      : log.response_status ?? "N/A"}
+                  </TableCell>
                 </TableRow>
               ))}
             </TableBody>
@@ -0,0 +1,33 @@
+import { useState, useEffect } from 'react';
+import { apiClient } from '@/lib/api-client';
+import { CampaignApproval } from '@/lib/types';
+
+export function useCampaignApprovals() {
+    const [approvals, setApprovals] = useState<CampaignApproval[]>([]);
+    const [loading, setLoading] = useState(true);
+    const [error, setError] = useState<Error | null>(null);
+
+    const fetchApprovals = async () => {
+        try {
+            setLoading(true);
+            const response = await apiClient.get<{ data: CampaignApproval[] }>('/campaign-approvals');
+            setApprovals(response.data.data);
+            setError(null);
+        } catch (err) {
+            setError(err instanceof Error ? err : new Error('An error occurred'));
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    useEffect(() => {
+        fetchApprovals();
+    }, []);
+
+    const decide = async (id: string, decision: 'approve' | 'reject') => {
+        await apiClient.post(`/campaign-approvals/${id}/${decision}`);
+        setApprovals(prev => prev.filter(approval => approval.id !== id));
+    };
+
+    return { approvals, loading, error, decide };
+}
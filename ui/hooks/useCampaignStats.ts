@@ -0,0 +1,28 @@
+import { useState, useEffect } from 'react';
+import { apiClient } from '@/lib/api-client';
+import { CampaignStatsSummary } from '@/lib/types';
+
+export function useCampaignStats(groupBy: 'sons' | 'posts') {
+    const [stats, setStats] = useState<CampaignStatsSummary[]>([]);
+    const [loading, setLoading] = useState(true);
+    const [error, setError] = useState<Error | null>(null);
+
+    const fetchStats = async () => {
+        try {
+            setLoading(true);
+            const response = await apiClient.get<{ data: CampaignStatsSummary[] }>(`/campaign-stats/${groupBy}`);
+            setStats(response.data.data);
+            setError(null);
+        } catch (err) {
+            setError(err instanceof Error ? err : new Error('An error occurred'));
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    useEffect(() => {
+        fetchStats();
+    }, [groupBy]);
+
+    return { stats, loading, error, fetchStats };
+}
@@ -0,0 +1,50 @@
+import { useState, useEffect, useCallback } from 'react';
+import { apiClient } from '@/lib/api-client';
+import { DeadLetter } from '@/lib/types';
+
+export function useDeadLetters() {
+    const [deadLetters, setDeadLetters] = useState<DeadLetter[]>([]);
+    const [total, setTotal] = useState(0);
+    const [loading, setLoading] = useState(true);
+    const [error, setError] = useState<Error | null>(null);
+
+    const fetchDeadLetters = useCallback(async () => {
+        try {
+            setLoading(true);
+            const response = await apiClient.get<{ data: DeadLetter[], pagination: { total: number } }>('/dead-letters?limit=100');
+            setDeadLetters(response.data.data);
+            setTotal(response.data.pagination.total);
+            setError(null);
+        } catch (err) {
+            setError(err instanceof Error ? err : new Error('An error occurred'));
+        } finally {
+            setLoading(false);
+        }
+    }, []);
+
+    useEffect(() => {
+        fetchDeadLetters();
+    }, [fetchDeadLetters]);
+
+    // Retries or discards the given dead letters, or all of them without ids
+    const apply = async (operation: 'retry' | 'discard', ids?: string[]) => {
+        try {
+            if (ids && ids.length === 1) {
+                if (operation === 'retry') {
+                    await apiClient.post(`/dead-letters/${ids[0]}/retry`);
+                } else {
+                    await apiClient.delete(`/dead-letters/${ids[0]}`);
+                }
+            } else {
+                await apiClient.post(`/dead-letters/${operation}`, { ids: ids ?? [] });
+            }
+        } finally {
+            await fetchDeadLetters();
+        }
+    };
+
+    const retry = (ids?: string[]) => apply('retry', ids);
+    const discard = (ids?: string[]) => apply('discard', ids);
+
+    return { deadLetters, total, loading, error, retry, discard, refresh: fetchDeadLetters };
+}
@@ -1,7 +1,7 @@
 // hooks/useSonLogs.ts
 import { useState, useEffect, useCallback } from 'react';
 import { apiClient } from '@/lib/api-client';
-import { SonExecutionLog, ActionExecutionLog, Son } from '@/lib/types';
+import { SonExecutionLog, ActionExecutionLog, ExecutionStep, Son } from '@/lib/types';
 
 interface Pagination {
     total: number;
@@ -18,11 +18,16 @@ export function useSonLogs() {
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState<Error | null>(null);
     const [pagination, setPagination] = useState<Pagination>({ total: 0, limit: 10, offset: 0 });
+    const [memberEmail, setMemberEmail] = useState('');
 
     const fetchLogs = useCallback(async (offset = 0) => {
         try {
             setLoading(true);
-            const response = await apiClient.get<{ logs: SonExecutionLog[], pagination: Pagination }>(`/son-execution-logs?offset=${offset}&limit=${pagination.limit}`);
+            const params = new URLSearchParams({ offset: String(offset), limit: String(pagination.limit) });
+            if (memberEmail) {
+                params.set('member_email', memberEmail);
+            }
+            const response = await apiClient.get<{ logs: SonExecutionLog[], pagination: Pagination }>(`/son-execution-logs?${params}`);
 
             const enhancedLogs = await Promise.all(response.data.logs.map(async (log) => {
                 try {
@@ -42,7 +47,7 @@ export function useSonLogs() {
         } finally {
             setLoading(false);
         }
-    }, [pagination.limit]);
+    }, [pagination.limit, memberEmail]);
 
     useEffect(() => {
         fetchLogs();
@@ -57,11 +62,20 @@ export function useSonLogs() {
         }
     };
 
+    const fetchSteps = async (executionId: string): Promise<ExecutionStep[]> => {
+        try {
+            const response = await apiClient.get<{ steps: ExecutionStep[] }>(`/son-executions/${executionId}/steps`);
+            return response.data.steps;
+        } catch (err) {
+            throw err instanceof Error ? err : new Error('An error occurred');
+        }
+    };
+
     const fetchNextPage = () => {
         if (pagination.offset + pagination.limit < pagination.total) {
             fetchLogs(pagination.offset + pagination.limit);
         }
     };
 
-    return { logs, loading, error, pagination, fetchLogs, fetchActionLogs, fetchNextPage };
+    return { logs, loading, error, pagination, memberEmail, setMemberEmail, fetchLogs, fetchActionLogs, fetchSteps, fetchNextPage };
 }
\ No newline at end of file
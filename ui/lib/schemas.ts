@@ -1,32 +1,117 @@
 import * as z from 'zod';
 
+export const triggerTypes = [
+    'member_created',
+    'member_deleted',
+    'member_updated',
+    'post_published',
+    'post_published_edited',
+    'post_scheduled',
+    'post_unpublished',
+    'post_edited',
+    'post_deleted',
+    'page_published',
+    'page_published_edited',
+    'page_unpublished',
+    'page_edited',
+    'page_deleted',
+    'tag_added',
+    'tag_deleted',
+    'site_changed',
+] as const;
+
+// Maps a Ghost member field to a Listmonk subscriber attribute
+const attributeMappingSchema = z.object({
+    source: z.string().min(1),
+    attribute: z.string().min(1),
+    type: z.enum(['string', 'number', 'boolean', 'date', 'timestamp', 'list']).optional(),
+    default: z.any().optional(),
+});
+
 // Define the schema for action parameters
 const actionParametersSchema = z.object({
     subject: z.string().optional(),
     lists: z.array(z.number()).optional(),
     template_id: z.number().optional(),
     tags: z.string().optional(),
+    attribute_mappings: z.array(attributeMappingSchema).optional(),
 }).strict().or(z.record(z.any())); // Allow any other properties for flexibility
 
 
 
+export const actionTypes = [
+    'send_transactional_email',
+    'manage_subscriber',
+    'create_campaign',
+    'remove_from_lists',
+    'unsubscribe_subscriber',
+    'blocklist_subscriber',
+    'delete_subscriber',
+    'http_request',
+] as const;
+
+// Offset of an action from the Son delay, e.g. '3d' for the second email of a
+// sequence. Delays anchored to a payload timestamp may be negative ('-1h').
+const actionDelaySchema = z.string().refine((val) => val === '' || /^-?(\d+)\s*(s|m|h|d|w)$/.test(val), {
+    message: "Invalid duration format. Use format like '30m', '2h', '3d', or '-1h'.",
+}).optional();
+
+const clockSchema = z.string().regex(/^([01]\d|2[0-3]):[0-5]\d$/, 'Use a 24h time like 09:00');
+
+// Daily time range an action is held back to, in the member's timezone when known
+const sendWindowSchema = z.preprocess(
+    (val: any) => (val && (val.start || val.end) ? val : undefined),
+    z.object({
+        start: clockSchema,
+        end: clockSchema,
+        timezone: z.string().optional(),
+    }).optional(),
+);
+
+const retryDurationSchema = z.string().regex(/^(\d+)\s*(s|m|h|d|w)$/, "Invalid duration format. Use format like '30s', '5m' or '1h'.");
+
+// How a failing action is retried; empty fields keep the defaults
+const retryPolicySchema = z.preprocess(
+    (val: any) => {
+        if (!val) return undefined;
+        const set = Object.fromEntries(Object.entries(val).filter(([, v]) => v !== '' && v !== undefined && v !== null));
+        return Object.keys(set).length > 0 ? set : undefined;
+    },
+    z.object({
+        max_retries: z.coerce.number().int().min(0).max(25).optional(),
+        backoff: z.enum(['exponential', 'linear', 'fixed']).optional(),
+        backoff_delay: retryDurationSchema.optional(),
+        timeout: retryDurationSchema.optional(),
+    }).optional(),
+);
+
 // Define the schema for a single action
 const actionSchema = z.object({
-    type: z.enum(['send_transactional_email', 'manage_subscriber', 'create_campaign']),
+    type: z.enum(actionTypes),
     parameters: actionParametersSchema,
+    delay: actionDelaySchema,
+    anchor: z.string().optional(),
+    send_window: sendWindowSchema,
+    retry: retryPolicySchema,
+});
+
+// Events that cancel the steps a member-triggered Son still has queued for the member
+export const exitConditions = ['member_deleted', 'member_paid', 'member_unsubscribed'] as const;
+
+// A member field change a member_updated Son is restricted to
+const fieldChangeSchema = z.object({
+    field: z.string().min(1),
+    change: z.enum(['changed', 'added', 'removed']).optional(),
+    from: z.string().optional(),
+    to: z.string().optional(),
+    value: z.string().optional(),
 });
 
 // Define the schema for the entire Son object
 export const sonSchema = z.object({
     id: z.string().optional(), // Optional because it might not be present when creating a new Son
     name: z.string().min(1, 'Name is required'),
-    trigger: z.enum([
-        'member_created',
-        'member_deleted',
-        'member_updated',
-        'post_published',
-        'post_scheduled',
-    ]),
+    trigger: z.enum(triggerTypes),
     delay: z.string().refine((val) => {
         const durationRegex = /^(\d+)\s*(s|m|h|d|w)$/;
         return durationRegex.test(val);
@@ -34,6 +119,9 @@ export const sonSchema = z.object({
         message: "Invalid duration format. Use format like '30m', '2h', '1d', or '1w'.",
     }).default('0s'),
     actions: z.array(actionSchema).min(1, 'At least one action is required'),
+    field_changes: z.array(fieldChangeSchema).optional(),
+    conditions: z.array(z.string()).optional(),
+    exit_conditions: z.array(z.enum(exitConditions)).optional(),
     enabled: z.boolean().default(true),
     created_at: z.date().optional(),
     updated_at: z.date().optional(),
@@ -41,13 +129,7 @@ export const sonSchema = z.object({
 
 export const editableSonSchema = z.object({
     name: z.string().min(1, 'Name is required'),
-    trigger: z.enum([
-        'member_created',
-        'member_deleted',
-        'member_updated',
-        'post_published',
-        'post_scheduled',
-    ]),
+    trigger: z.enum(triggerTypes),
     delay: z.string().min(2).default('0s').refine((val) => {
         const durationRegex = /^(\d+)\s*(s|m|h|d|w)$/;
         return durationRegex.test(val);
@@ -55,9 +137,16 @@ export const editableSonSchema = z.object({
         message: "Invalid duration format. Use format like '30m', '2h', '1d', or '1w'.",
     }),
     actions: z.array(z.object({
-        type: z.enum(['send_transactional_email', 'manage_subscriber', 'create_campaign']),
+        type: z.enum(actionTypes),
         parameters: z.record(z.any()),
+        delay: actionDelaySchema,
+        anchor: z.string().optional(),
+        send_window: sendWindowSchema,
+        retry: retryPolicySchema,
     })),
+    field_changes: z.array(fieldChangeSchema).optional(),
+    conditions: z.array(z.string()).optional(),
+    exit_conditions: z.array(z.enum(exitConditions)).optional(),
     enabled: z.boolean().default(true),
 });
 
@@ -33,11 +33,21 @@ export interface CampaignActionFieldsProps {
     templates: ListmonkTemplate[];
 }
 
+// Maps a Ghost member field to a Listmonk subscriber attribute
+export interface AttributeMapping {
+    source: string;
+    attribute: string;
+    type?: string;
+    default?: string;
+}
+
 // Props for ManageSubscriberActionFields
 export interface ManageSubscriberActionFieldsProps {
     form: UseFormReturn<EditableSon>;
     index: number;
     lists: ListmonkList[];
+    // Used by remove_from_lists, which only needs the lists to remove from
+    removing?: boolean;
 }
 
 // Props for SonDetailsForm
@@ -72,11 +82,23 @@ export interface WebhookLog {
     duration: number;
 }
 
+// The state of an action, or of an execution computed from its actions
+export type ExecutionState =
+    | 'pending'
+    | 'queued'
+    | 'running'
+    | 'succeeded'
+    | 'failed'
+    | 'partially_failed'
+    | 'cancelled'
+    | 'skipped';
+
 export interface SonExecutionLog {
     id: string;
     son_id: string;
     webhook_log_id: string;
-    status: 'pending' | 'success' | 'failure' | 'warning';
+    member_email?: string;
+    status: ExecutionState;
     executed_at: string;
     error_message: string | null;
 }
@@ -85,9 +107,53 @@ export interface ActionExecutionLog {
     id: string;
     son_execution_log_id: string;
     action_type: string;
-    status: string;
+    status: ExecutionState;
     executed_at: string;
     error_message: string | null;
+    response_status?: number;
+    response_body?: string;
+    campaign_id?: number;
+}
+
+export interface ExecutionStep {
+    id: string;
+    execution_log_id: string;
+    action_index: number;
+    action_type: string;
+    task_id?: string;
+    queue: string;
+    status: Exclude<ExecutionState, 'partially_failed'>;
+    scheduled_for: string;
+    finished_at?: string;
+    error_message?: string;
+}
+
+// An action archived after it ran out of retries
+export interface DeadLetter {
+    id: string;
+    queue: string;
+    action_type: string;
+    action_index?: number;
+    son_id: string;
+    son_name?: string;
+    execution_id: string;
+    payload: Record<string, any>;
+    last_error: string;
+    last_failed_at: string;
+    retried: number;
+    max_retry: number;
+}
+
+export interface CampaignApproval {
+    id: string;
+    son_id?: string;
+    campaign_id: number;
+    campaign_name: string;
+    test_emails: string[];
+    status: 'pending' | 'approved' | 'rejected' | 'expired';
+    expires_at: string;
+    decided_at?: string;
+    created_at: string;
 }
 
 export interface Pagination {
@@ -110,4 +176,29 @@ export interface SonStats {
     executions: number;
     success: number;
     failure: number;
-}
\ No newline at end of file
+}
+
+export interface CampaignStatsSummary {
+    son_id?: string;
+    post_id?: string;
+    name: string;
+    campaigns: number;
+    sent: number;
+    views: number;
+    clicks: number;
+    bounces: number;
+}
+
+export interface TemplateError {
+    template: 'subject' | 'body';
+    line?: number;
+    column?: number;
+    message: string;
+}
+
+export interface TemplatePreview {
+    subject: string;
+    body: string;
+    warnings: TemplateError[];
+    errors: TemplateError[];
+}
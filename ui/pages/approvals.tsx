@@ -0,0 +1,15 @@
+import { NextPageWithExtras } from "next";
+import { CampaignApprovalsTable } from "@/components/CampaignApprovalsTable";
+
+const ApprovalsPage: NextPageWithExtras = () => {
+  return (
+    <div className="container mx-auto py-10">
+      <h1 className="text-2xl font-bold mb-5">Campaign Approvals</h1>
+      <CampaignApprovalsTable />
+    </div>
+  );
+};
+
+ApprovalsPage.auth = true;
+
+export default ApprovalsPage;
@@ -0,0 +1,15 @@
+import { NextPageWithExtras } from "next";
+import { DeadLettersTable } from "@/components/DeadLettersTable";
+
+const DeadLettersPage: NextPageWithExtras = () => {
+  return (
+    <div className="container mx-auto py-10">
+      <h1 className="text-2xl font-bold mb-5">Failed Actions</h1>
+      <DeadLettersTable />
+    </div>
+  );
+};
+
+DeadLettersPage.auth = true;
+
+export default DeadLettersPage;
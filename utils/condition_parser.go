@@ -0,0 +1,515 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a parsed boolean expression evaluated against webhook data.
+//
+// The language is intentionally small: dotted paths into the payload
+// (member.current.status), string, number, boolean and null literals, the
+// comparison operators == != < <= > >= contains and in, the logical
+// operators and/or/not (&&, ||, !) and parentheses. It has no function
+// calls or assignments, so user-supplied conditions cannot do anything but
+// read the payload.
+type Condition struct {
+	source string
+	root   conditionNode
+}
+
+// ParseCondition parses a condition expression, returning an error that
+// points at the offending position when the syntax is invalid.
+func ParseCondition(source string) (*Condition, error) {
+	tokens, err := tokenizeCondition(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return &Condition{source: source, root: root}, nil
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Evaluate reports whether the condition holds for the given data. Paths
+// that do not exist in the data evaluate to null.
+func (c *Condition) Evaluate(data map[string]interface{}) bool {
+	return truthy(c.root.eval(data))
+}
+
+type conditionNode interface {
+	eval(data map[string]interface{}) interface{}
+}
+
+type literalNode struct{ value interface{} }
+
+type pathNode struct{ path []string }
+
+type notNode struct{ operand conditionNode }
+
+type logicalNode struct {
+	op          string
+	left, right conditionNode
+}
+
+type comparisonNode struct {
+	op          string
+	left, right conditionNode
+}
+
+func (n literalNode) eval(map[string]interface{}) interface{} { return n.value }
+
+func (n pathNode) eval(data map[string]interface{}) interface{} {
+	return resolvePath(data, n.path)
+}
+
+func (n notNode) eval(data map[string]interface{}) interface{} {
+	return !truthy(n.operand.eval(data))
+}
+
+func (n logicalNode) eval(data map[string]interface{}) interface{} {
+	left := truthy(n.left.eval(data))
+	if n.op == "and" {
+		return left && truthy(n.right.eval(data))
+	}
+	return left || truthy(n.right.eval(data))
+}
+
+func (n comparisonNode) eval(data map[string]interface{}) interface{} {
+	left := n.left.eval(data)
+	right := n.right.eval(data)
+
+	switch n.op {
+	case "==":
+		return valuesEqual(left, right)
+	case "!=":
+		return !valuesEqual(left, right)
+	case "contains":
+		return valueContains(left, right)
+	case "in":
+		return valueContains(right, left)
+	default:
+		cmp, ok := compareValues(left, right)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		case ">=":
+			return cmp >= 0
+		}
+	}
+	return false
+}
+
+// ResolvePath returns the value at a dotted path, with the same semantics
+// conditions use.
+func ResolvePath(value interface{}, path string) interface{} {
+	if path == "" {
+		return value
+	}
+	return resolvePath(value, strings.Split(path, "."))
+}
+
+// resolvePath walks a dotted path through nested objects. When it meets a
+// list, the rest of the path is applied to every element, so
+// post.current.tags.slug yields the slugs of all tags. Strings holding a JSON
+// object, like member geolocation, are decoded so their fields can be reached.
+func resolvePath(value interface{}, path []string) interface{} {
+	for i, key := range path {
+		if str, ok := value.(string); ok && strings.HasPrefix(str, "{") {
+			var decoded map[string]interface{}
+			if err := json.Unmarshal([]byte(str), &decoded); err == nil {
+				value = decoded
+			}
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			if index, err := strconv.Atoi(key); err == nil {
+				if index < 0 || index >= len(v) {
+					return nil
+				}
+				value = v[index]
+				continue
+			}
+			results := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				results = append(results, resolvePath(item, path[i:]))
+			}
+			return results
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func valuesEqual(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if cmp, ok := compareValues(left, right); ok {
+		return cmp == 0
+	}
+	if l, ok := left.(bool); ok {
+		r, ok := right.(bool)
+		return ok && l == r
+	}
+	// Objects such as tags or labels equal a string matching their id, slug or name
+	if obj, ok := left.(map[string]interface{}); ok {
+		return objectMatches(obj, right)
+	}
+	if obj, ok := right.(map[string]interface{}); ok {
+		return objectMatches(obj, left)
+	}
+	return false
+}
+
+func objectMatches(obj map[string]interface{}, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, key := range []string{"id", "slug", "name"} {
+		if v, ok := obj[key].(string); ok && v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func valueContains(container, item interface{}) bool {
+	switch c := container.(type) {
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(c, s)
+	case []interface{}:
+		for _, element := range c {
+			if valuesEqual(element, item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		s, ok := item.(string)
+		if !ok {
+			return false
+		}
+		_, exists := c[s]
+		return exists
+	}
+	return false
+}
+
+// compareValues orders two numbers or two strings. Strings compare
+// lexically, which also orders the ISO-8601 timestamps Ghost sends.
+func compareValues(left, right interface{}) (int, bool) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch {
+			case l < r:
+				return -1, true
+			case l > r:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), true
+		}
+	}
+	return 0, false
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type conditionToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var keywordOperators = map[string]string{
+	"and":      "and",
+	"or":       "or",
+	"not":      "not",
+	"contains": "contains",
+	"in":       "in",
+}
+
+func tokenizeCondition(source string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, conditionToken{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, conditionToken{tokenRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, conditionToken{tokenString, sb.String(), start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			word := string(runes[start:i])
+			if op, ok := keywordOperators[strings.ToLower(word)]; ok {
+				tokens = append(tokens, conditionToken{tokenOperator, op, start})
+			} else {
+				tokens = append(tokens, conditionToken{tokenIdent, word, start})
+			}
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch {
+			case two == "==" || two == "!=" || two == "<=" || two == ">=":
+				tokens = append(tokens, conditionToken{tokenOperator, two, start})
+				i += 2
+			case two == "&&":
+				tokens = append(tokens, conditionToken{tokenOperator, "and", start})
+				i += 2
+			case two == "||":
+				tokens = append(tokens, conditionToken{tokenOperator, "or", start})
+				i += 2
+			case r == '<' || r == '>':
+				tokens = append(tokens, conditionToken{tokenOperator, string(r), start})
+				i++
+			case r == '!':
+				tokens = append(tokens, conditionToken{tokenOperator, "not", start})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+			}
+		}
+	}
+
+	return append(tokens, conditionToken{tokenEOF, "end of expression", len(runes)}), nil
+}
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() conditionToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *conditionParser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseNot() (conditionNode, error) {
+	if p.isOperator("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	// "a not contains b" and "a not in b" read naturally, so support them
+	negate := false
+	if p.isOperator("not") {
+		p.next()
+		if !p.isOperator("contains", "in") {
+			tok := p.peek()
+			return nil, fmt.Errorf("expected contains or in after not at position %d", tok.pos)
+		}
+		negate = true
+	}
+
+	if !p.isOperator("==", "!=", "<", "<=", ">", ">=", "contains", "in") {
+		return left, nil
+	}
+	op := p.next().text
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	var node conditionNode = comparisonNode{op: op, left: left, right: right}
+	if negate {
+		node = notNode{operand: node}
+	}
+	return node, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return node, nil
+	case tokenString:
+		return literalNode{value: tok.text}, nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return literalNode{value: n}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		path := strings.Split(tok.text, ".")
+		for _, segment := range path {
+			if segment == "" {
+				return nil, fmt.Errorf("invalid path %q at position %d", tok.text, tok.pos)
+			}
+		}
+		return pathNode{path: path}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const conditionPayload = `{
+	"member": {
+		"current": {
+			"email": "jamie@example.com",
+			"status": "paid",
+			"email_count": 3,
+			"labels": [{"id": "l1", "name": "VIP", "slug": "vip"}],
+			"geolocation": "{\"country_code\":\"ES\",\"timezone\":\"Europe/Madrid\"}"
+		},
+		"previous": {"status": "free"}
+	},
+	"post": {
+		"current": {
+			"tags": [
+				{"name": "News", "slug": "news"},
+				{"name": "Tech", "slug": "tech"}
+			]
+		}
+	}
+}`
+
+func conditionData(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(conditionPayload), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		want      bool
+	}{
+		// Precedence: not binds tighter than and, which binds tighter than or
+		{"and before or", `true or false and false`, true},
+		{"parentheses override", `(true or false) and false`, false},
+		{"not before and", `not false and false`, false},
+		{"not of group", `not (false and false)`, true},
+		{"symbolic operators", `!false && (false || true)`, true},
+		{"or of comparisons", `member.current.status == "comped" or member.current.status == "paid"`, true},
+
+		{"in object list", `"vip" in member.current.labels`, true},
+		{"not in object list", `"vip" not in member.current.labels`, false},
+		{"not in missing label", `"gold" not in member.current.labels`, true},
+		{"contains string", `member.current.email contains "@example.com"`, true},
+		{"not contains string", `member.current.email not contains "@example.com"`, false},
+		{"not contains other string", `member.current.email not contains "@spam"`, true},
+
+		{"path through list", `post.current.tags.slug contains "tech"`, true},
+		{"path through list misses", `post.current.tags.slug contains "sport"`, false},
+		{"list index", `post.current.tags.0.name == "News"`, true},
+		{"list index out of range", `post.current.tags.5.name == null`, true},
+		{"JSON string object", `member.current.geolocation.country_code == "ES"`, true},
+
+		{"missing path is null", `member.current.unknown == null`, true},
+		{"missing path is falsy", `member.current.unknown`, false},
+		{"missing nested path", `missing.deep.path != "x"`, true},
+		{"missing path does not order", `member.current.unknown > 1`, false},
+
+		{"number comparison", `member.current.email_count >= 3`, true},
+		{"string ordering", `member.previous.status < member.current.status`, true},
+		{"mismatched types", `member.current.email_count == "3"`, false},
+	}
+
+	data := conditionData(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := ParseCondition(tt.condition)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q): %v", tt.condition, err)
+			}
+			if got := condition.Evaluate(data); got != tt.want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      string
+	}{
+		{`member.status ==`, `unexpected "end of expression" at position 16`},
+		{`(true`, `expected ) at position 5`},
+		{`"abc`, `unterminated string at position 0`},
+		{`a # b`, `unexpected character '#' at position 2`},
+		{`a not == b`, `expected contains or in after not at position 6`},
+		{`a b`, `unexpected "b" at position 2`},
+		{`a..b == 1`, `invalid path "a..b" at position 0`},
+		{`1.2.3 == 1`, `invalid number "1.2.3" at position 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			_, err := ParseCondition(tt.condition)
+			if err == nil {
+				t.Fatalf("ParseCondition(%q) succeeded, want error %q", tt.condition, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCondition(%q) error = %q, want %q", tt.condition, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"member.current.status", "paid"},
+		{"member.current.geolocation.timezone", "Europe/Madrid"},
+		{"post.current.tags.slug", []interface{}{"news", "tech"}},
+		{"post.current.tags.1.name", "Tech"},
+		{"post.current.tags.-1.name", nil},
+		{"member.current.unknown", nil},
+		{"member.current.status.deeper", nil},
+	}
+
+	data := conditionData(t)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ResolvePath(data, tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolvePath(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if got := ResolvePath(data, ""); !reflect.DeepEqual(got, data) {
+		t.Errorf("ResolvePath with an empty path = %#v, want the data itself", got)
+	}
+}
@@ -33,6 +33,22 @@ type Config struct {
 
 	// Redis configuration
 	RedisAddr string
+
+	// Webhook signature configuration
+	WebhookTimestampTolerance string
+
+	// How long accepted deliveries are remembered for deduplication
+	WebhookDedupTTL string
+
+	// How often campaign stats are fetched from Listmonk
+	CampaignStatsInterval string
+
+	// How often to look for campaigns the connector created but never
+	// scheduled, how old a draft must be to count, and whether to "report"
+	// or "delete" them
+	OrphanedCampaignSweepInterval string
+	OrphanedCampaignAge           string
+	OrphanedCampaignAction        string
 }
 
 var (
@@ -104,6 +120,25 @@ func LoadConfig() (*Config, error) {
 		config.RedisAddr = envRedisAddr
 	}
 
+	if envTolerance := os.Getenv("WEBHOOK_TIMESTAMP_TOLERANCE"); envTolerance != "" {
+		config.WebhookTimestampTolerance = envTolerance
+	}
+	if envDedupTTL := os.Getenv("WEBHOOK_DEDUP_TTL"); envDedupTTL != "" {
+		config.WebhookDedupTTL = envDedupTTL
+	}
+	if envStatsInterval := os.Getenv("CAMPAIGN_STATS_INTERVAL"); envStatsInterval != "" {
+		config.CampaignStatsInterval = envStatsInterval
+	}
+	if envSweepInterval := os.Getenv("ORPHANED_CAMPAIGN_SWEEP_INTERVAL"); envSweepInterval != "" {
+		config.OrphanedCampaignSweepInterval = envSweepInterval
+	}
+	if envOrphanedAge := os.Getenv("ORPHANED_CAMPAIGN_AGE"); envOrphanedAge != "" {
+		config.OrphanedCampaignAge = envOrphanedAge
+	}
+	if envOrphanedAction := os.Getenv("ORPHANED_CAMPAIGN_ACTION"); envOrphanedAction != "" {
+		config.OrphanedCampaignAction = envOrphanedAction
+	}
+
 	// Validate required fields
 	if config.ListmonkURL == "" {
 		return nil, fmt.Errorf("LISTMONK_URL is not set")
@@ -147,6 +182,42 @@ func LoadConfig() (*Config, error) {
 	if config.RedisAddr == "" {
 		return nil, fmt.Errorf("REDIS_ADDR is not set")
 	}
+	if config.WebhookTimestampTolerance == "" {
+		config.WebhookTimestampTolerance = "5m" // Default tolerance if not set
+	}
+	if _, err := ParseDuration(config.WebhookTimestampTolerance); err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_TIMESTAMP_TOLERANCE: %w", err)
+	}
+	if config.WebhookDedupTTL == "" {
+		config.WebhookDedupTTL = "24h" // Default deduplication window if not set
+	}
+	if _, err := ParseDuration(config.WebhookDedupTTL); err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DEDUP_TTL: %w", err)
+	}
+	if config.CampaignStatsInterval == "" {
+		config.CampaignStatsInterval = "15m" // Default stats refresh interval if not set
+	}
+	if _, err := ParseDuration(config.CampaignStatsInterval); err != nil {
+		return nil, fmt.Errorf("invalid CAMPAIGN_STATS_INTERVAL: %w", err)
+	}
+	if config.OrphanedCampaignSweepInterval == "" {
+		config.OrphanedCampaignSweepInterval = "1h" // Default sweep interval if not set
+	}
+	if _, err := ParseDuration(config.OrphanedCampaignSweepInterval); err != nil {
+		return nil, fmt.Errorf("invalid ORPHANED_CAMPAIGN_SWEEP_INTERVAL: %w", err)
+	}
+	if config.OrphanedCampaignAge == "" {
+		config.OrphanedCampaignAge = "24h" // Default age of an orphaned draft if not set
+	}
+	if _, err := ParseDuration(config.OrphanedCampaignAge); err != nil {
+		return nil, fmt.Errorf("invalid ORPHANED_CAMPAIGN_AGE: %w", err)
+	}
+	if config.OrphanedCampaignAction == "" {
+		config.OrphanedCampaignAction = "report" // Only log orphaned campaigns if not set
+	}
+	if config.OrphanedCampaignAction != "report" && config.OrphanedCampaignAction != "delete" {
+		return nil, fmt.Errorf("invalid ORPHANED_CAMPAIGN_ACTION: must be report or delete, got %s", config.OrphanedCampaignAction)
+	}
 
 	return config, nil
 }
@@ -201,6 +272,18 @@ func loadEnvFile(filename string, config *Config) error {
 			config.DBPassword = value
 		case "REDIS_ADDR":
 			config.RedisAddr = value
+		case "WEBHOOK_TIMESTAMP_TOLERANCE":
+			config.WebhookTimestampTolerance = value
+		case "WEBHOOK_DEDUP_TTL":
+			config.WebhookDedupTTL = value
+		case "CAMPAIGN_STATS_INTERVAL":
+			config.CampaignStatsInterval = value
+		case "ORPHANED_CAMPAIGN_SWEEP_INTERVAL":
+			config.OrphanedCampaignSweepInterval = value
+		case "ORPHANED_CAMPAIGN_AGE":
+			config.OrphanedCampaignAge = value
+		case "ORPHANED_CAMPAIGN_ACTION":
+			config.OrphanedCampaignAction = value
 
 			// Add other configuration fields as needed
 		}
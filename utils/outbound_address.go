@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for outbound requests to an internal
+// address, such as loopback, a private network or cloud metadata
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// IsPublicIP reports whether an outbound request may reach ip. Loopback,
+// private, link-local (including 169.254.169.254), multicast and
+// unspecified addresses are refused.
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// PublicAddressControl is a net.Dialer Control hook refusing connections to
+// addresses that are not public. It runs after DNS resolution, so a host
+// name pointing at an internal address is refused as well.
+func PublicAddressControl(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
@@ -2,22 +2,71 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
 	"html/template"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
 )
 
-func ParseTemplate(templateString string, postData map[string]interface{}) (string, error) {
-	tmpl, err := template.New("email").Parse(templateString)
+// templateFuncs are available in every template. They never fail on missing
+// or malformed values, so a field absent from a payload renders as empty
+// instead of aborting the campaign:
+//
+//	date "Jan 2, 2006" .Post.PublishedAt   formats an RFC 3339 string or time
+//	truncate 140 .Post.Excerpt             shortens text to n characters
+//	absURL .Site.URL "/tag/news/"          resolves a path against a base URL
+//	default "Subscriber" .member.name      falls back when the value is empty
+//	json .member.current.email             encodes a value as JSON
+var templateFuncs = map[string]interface{}{
+	"date":     formatDate,
+	"truncate": truncate,
+	"absURL":   absURL,
+	"default":  defaultValue,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// CompileTemplate parses an HTML template, such as a campaign body
+func CompileTemplate(name string, templateString string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap(templateFuncs)).Parse(templateString)
+}
+
+// RenderTemplate renders an HTML template. Values are escaped unless they
+// are template.HTML.
+func RenderTemplate(name string, templateString string, data interface{}) (string, error) {
+	tmpl, err := CompileTemplate(name, templateString)
 	if err != nil {
 		return "", err
 	}
 
-	// Convert the HTML content to template.HTML type
-	if html, ok := postData["Html"].(string); ok {
-		postData["Html"] = template.HTML(html)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
 	}
 
-	data := map[string]interface{}{
-		"Post": postData,
+	return buf.String(), nil
+}
+
+// CompileTextTemplate parses a plain text template, such as an outbound
+// request body or an email subject.
+func CompileTextTemplate(name string, templateString string) (*texttemplate.Template, error) {
+	return texttemplate.New(name).Funcs(texttemplate.FuncMap(templateFuncs)).Parse(templateString)
+}
+
+// RenderTextTemplate renders a plain text template without escaping
+func RenderTextTemplate(name string, templateString string, data interface{}) (string, error) {
+	tmpl, err := CompileTextTemplate(name, templateString)
+	if err != nil {
+		return "", err
 	}
 
 	var buf bytes.Buffer
@@ -27,3 +76,171 @@ func ParseTemplate(templateString string, postData map[string]interface{}) (stri
 
 	return buf.String(), nil
 }
+
+func formatDate(layout string, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(layout)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatDate(layout, *v)
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return ""
+		}
+		return t.Format(layout)
+	}
+	return ""
+}
+
+func truncate(length int, value interface{}) string {
+	s, _ := value.(string)
+	if length < 0 || utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:length])) + "…"
+}
+
+func absURL(base string, path string) string {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return ""
+	}
+	if ref.IsAbs() {
+		return path
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil || !baseURL.IsAbs() {
+		return path
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return fallback
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return fallback
+		}
+	}
+	return value
+}
+
+// TemplateError is a template parse or execution error with its position
+type TemplateError struct {
+	Template string `json:"template"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+var templateErrorPattern = regexp.MustCompile(`^template: ([^:]+):(\d+)(?::(\d+))?: (.*)$`)
+
+// NewTemplateError extracts the line and column Go's template errors
+// embed in their message, e.g. "template: body:3:7: executing ...".
+func NewTemplateError(name string, err error) TemplateError {
+	templateErr := TemplateError{Template: name, Message: err.Error()}
+	if m := templateErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		templateErr.Line, _ = strconv.Atoi(m[2])
+		templateErr.Column, _ = strconv.Atoi(m[3])
+		templateErr.Message = m[4]
+	}
+	return templateErr
+}
+
+// TemplateField is a field a template reads from its root data, such as
+// Post.Title for {{ .Post.Title }}
+type TemplateField struct {
+	Path []string
+	Line int
+}
+
+// TemplateFields lists the root data fields a template reads. Fields inside
+// range and with blocks are relative to another value and are left out,
+// except for those read through $.
+func TemplateFields(templateString string) ([]TemplateField, error) {
+	tmpl, err := CompileTextTemplate("fields", templateString)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []TemplateField
+	add := func(pos parse.Pos, path []string) {
+		if len(path) > 0 {
+			fields = append(fields, TemplateField{Path: path, Line: strings.Count(templateString[:pos], "\n") + 1})
+		}
+	}
+
+	var walk func(node parse.Node, root bool)
+	walk = func(node parse.Node, root bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, root)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, root)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, root)
+			}
+		case *parse.CommandNode:
+			args := n.Args
+			// The value given to default is allowed to be empty
+			if ident, ok := args[0].(*parse.IdentifierNode); ok && ident.Ident == "default" {
+				args = args[:len(args)-1]
+			}
+			for _, arg := range args {
+				walk(arg, root)
+			}
+		case *parse.FieldNode:
+			if root {
+				add(n.Pos, n.Ident)
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				add(n.Pos, n.Ident[1:])
+			}
+		case *parse.IfNode:
+			walk(n.Pipe, root)
+			walk(n.List, root)
+			walk(n.ElseList, root)
+		case *parse.RangeNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.WithNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.TemplateNode:
+			walk(n.Pipe, root)
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root, true)
+	}
+
+	return fields, nil
+}